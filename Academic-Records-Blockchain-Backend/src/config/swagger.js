@@ -92,7 +92,8 @@ This API drives the full lifecycle of academic credentials:
                         docId: { type: 'string' },
                         studentID: { type: 'string' },
                         sha256Hash: { type: 'string' },
-                        docType: { type: 'string' },
+                        docType: { type: 'string', description: 'Asset type, always "document"' },
+                        documentType: { type: 'string', description: 'GRADE_SHEET, DEGREE_CERT, TRANSCRIPT, AADHAAR, PHOTO, OTHER' },
                         semester: { type: 'integer' },
                         academicYear: { type: 'string' },
                         uploadedAt: { type: 'string', format: 'date-time' }
@@ -110,7 +110,12 @@ const login = async (req, res) => {
                     logger.info(`Fabric identity missing for ${walletKey} (${user.role}), enrolling now...`);
                     await caClient.registerUser(
                         walletKey,
-                        { role: user.role, department: user.department || '', email: user.email },
+                        {
+                            role: user.role,
+                            department: user.department || '',
+                            email: user.email,
+                            rollNumber: user.role === 'student' ? user.username : undefined
+                        },
                         'client',
                         ''
                     );
@@ -234,9 +239,11 @@ const register = async (req, res) => {
                     const rollNumber = username;
                     const enrollmentYear = new Date().getFullYear();
 
+                    const transientData = { phone: '0000000000', personalEmail: email };
+                    if (req.body.aadhaar) transientData.aadhaar = String(req.body.aadhaar);
                     await gateway.submitTransactionWithTransient(
                         'CreateStudent',
-                        { aadhaarHash: `HASH-${rollNumber}`, phone: '0000000000', personalEmail: email },
+                        transientData,
                         rollNumber,
                         studentName,
                         department || 'CSE',
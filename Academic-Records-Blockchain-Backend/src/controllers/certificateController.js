@@ -119,7 +119,7 @@ class CertificateController {
             await gateway.connect(userId);
 
             // Call the correct chaincode function: GetCertificatesByStudent
-            const result = await gateway.evaluateTransaction('GetCertificatesByStudent', studentID);
+            const result = await gateway.evaluateAllPages('GetCertificatesByStudent', studentID);
 
             res.status(200).json({
                 success: true,
@@ -149,8 +149,7 @@ const getStudentConsents = async (req, res) => {
         await gateway.connect(req.user);
         let consents = [];
         try {
-            const result = await gateway.evaluateTransaction('GetConsentsByStudent', studentId);
-            consents = JSON.parse(result.toString());
+            consents = await gateway.evaluateAllPages('GetConsentsByStudent', studentId);
         } catch {
             // Fall back to local store
             consents = loadConsents().filter(c => c.studentId === studentId);
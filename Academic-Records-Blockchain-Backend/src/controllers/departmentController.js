@@ -51,7 +51,7 @@ class DepartmentController {
             }
 
             await gateway.connect(req.user);
-            const result = await gateway.evaluateTransaction('GetAllDepartments');
+            const result = await gateway.evaluateAllPages('GetAllDepartments');
 
             // Handle empty or null response gracefully
             let departments = [];
@@ -94,7 +94,7 @@ class DepartmentController {
             }
 
             await gateway.connect(req.user);
-            const result = await gateway.evaluateTransaction('GetCoursesByDepartment', departmentId);
+            const result = await gateway.evaluateAllPages('GetCoursesByDepartment', departmentId);
 
             let courses = [];
             if (result && typeof result === 'object') {
@@ -294,7 +294,8 @@ class DepartmentController {
     }
 
     /**
-     * Get academic records submitted by department
+     * Get academic records submitted by department, optionally filtered by semester,
+     * academic year and status
      */
     static async getRecordsByDepartment(req, res) {
         const gateway = new FabricGateway();
@@ -311,21 +312,15 @@ class DepartmentController {
 
             await gateway.connect(req.user);
 
-            let departmentRecords = [];
-
-            try {
-                const pendingResult = await gateway.evaluateTransaction('QueryPendingRecords', '', '1000');
-                const pendingData = JSON.parse(pendingResult.toString());
-                const pending = Array.isArray(pendingData) ? pendingData : (pendingData.records || []);
-                departmentRecords.push(...pending.filter(r => r.department === departmentId));
-            } catch (e) { /* no pending records */ }
-
-            try {
-                const approvedResult = await gateway.evaluateTransaction('QueryRecordsByStatus', 'APPROVED', '', '1000');
-                const approvedData = JSON.parse(approvedResult.toString());
-                const approved = Array.isArray(approvedData) ? approvedData : (approvedData.records || []);
-                departmentRecords.push(...approved.filter(r => r.department === departmentId));
-            } catch (e) { /* no approved records */ }
+            // Optional filters: ?semester=3&year=2024-25&status=SUBMITTED
+            const { semester = '0', year = '', status = '' } = req.query;
+            const departmentRecords = await gateway.evaluateAllPages(
+                'QueryRecordsByDepartment',
+                departmentId,
+                semester.toString(),
+                year,
+                status
+            );
 
             res.status(200).json({
                 success: true,
@@ -339,6 +334,129 @@ class DepartmentController {
             await gateway.disconnect();
         }
     }
+
+    /**
+     * Get result analytics (pass percentages, grade histograms, SGPA distribution and
+     * top students) over the finalized records of a semester and academic year
+     */
+    static async getResultAnalytics(req, res) {
+        const gateway = new FabricGateway();
+        try {
+            let { departmentId } = req.params;
+            departmentId = departmentId.toUpperCase();
+            const { semester, year, top = '10' } = req.query;
+
+            const userDept = req.user.department ? req.user.department.toUpperCase() : null;
+            if (req.user.role !== 'admin' && userDept !== departmentId) {
+                return res.status(403).json({ success: false, message: 'Access denied' });
+            }
+            if (!semester || !year) {
+                return res.status(400).json({ success: false, message: 'semester and year query parameters are required' });
+            }
+
+            await gateway.connect(req.user);
+            const analytics = await gateway.evaluateTransaction(
+                'GetDepartmentResultAnalytics',
+                departmentId,
+                semester.toString(),
+                year,
+                top.toString()
+            );
+
+            res.status(200).json({ success: true, data: analytics });
+        } catch (error) {
+            logger.error('Error getting result analytics:', error);
+            res.status(500).json({ success: false, message: error.message });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
+    /**
+     * Generate an immutable merit list for a batch as of a cutoff date (admin only)
+     */
+    static async generateMeritList(req, res) {
+        const gateway = new FabricGateway();
+        try {
+            const departmentId = req.params.departmentId.toUpperCase();
+            const { batch, cutoffDate } = req.body;
+
+            if (!batch || !cutoffDate) {
+                return res.status(400).json({ success: false, message: 'batch and cutoffDate (YYYY-MM-DD) are required' });
+            }
+
+            await gateway.connect(req.user);
+            const meritList = await gateway.submitTransaction('GenerateMeritList', departmentId, batch.toString(), cutoffDate);
+
+            logger.info(`Merit list ${meritList.meritListId} generated`);
+            res.status(201).json({ success: true, data: meritList });
+        } catch (error) {
+            logger.error('Error generating merit list:', error);
+            res.status(500).json({ success: false, message: error.message });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
+    /**
+     * List a department's merit lists, optionally for one batch (?batch=2022)
+     */
+    static async getMeritLists(req, res) {
+        const gateway = new FabricGateway();
+        try {
+            const departmentId = req.params.departmentId.toUpperCase();
+            const { batch = '0' } = req.query;
+
+            const userDept = req.user.department ? req.user.department.toUpperCase() : null;
+            if (req.user.role !== 'admin' && userDept !== departmentId) {
+                return res.status(403).json({ success: false, message: 'Access denied' });
+            }
+
+            await gateway.connect(req.user);
+            const meritLists = await gateway.evaluateAllPages('GetMeritListsByDepartment', departmentId, batch.toString());
+
+            res.status(200).json({ success: true, data: meritLists });
+        } catch (error) {
+            logger.error('Error getting merit lists:', error);
+            res.status(500).json({ success: false, message: error.message });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
+    /**
+     * Get one merit list
+     */
+    static async getMeritList(req, res) {
+        const gateway = new FabricGateway();
+        try {
+            await gateway.connect(req.user);
+            const meritList = await gateway.evaluateTransaction('GetMeritList', req.params.meritListId);
+            res.status(200).json({ success: true, data: meritList });
+        } catch (error) {
+            logger.error('Error getting merit list:', error);
+            res.status(500).json({ success: false, message: error.message });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
+    /**
+     * Verify a merit list against its ledger hash, optionally against a published hash (?hash=)
+     */
+    static async verifyMeritList(req, res) {
+        const gateway = new FabricGateway();
+        try {
+            await gateway.connect(req.user);
+            const verification = await gateway.evaluateTransaction('VerifyMeritList', req.params.meritListId, req.query.hash || '');
+            res.status(200).json({ success: true, data: verification });
+        } catch (error) {
+            logger.error('Error verifying merit list:', error);
+            res.status(500).json({ success: false, message: error.message });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
 }
 
 module.exports = DepartmentController;
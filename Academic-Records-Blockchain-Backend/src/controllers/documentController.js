@@ -229,7 +229,7 @@ const createNewVersion = async (req, res) => {
             'UploadDocument',
             newDocId,
             existingDoc.studentID,
-            existingDoc.docType,
+            existingDoc.documentType,
             sha256Hash,
             req.file.originalname,
             existingDoc.academicYear || '',
@@ -290,14 +290,16 @@ const verifyDocument = async (req, res) => {
         let verified = false, blockchainRecord = null;
         try {
             blockchainRecord = await gateway.evaluateTransaction('VerifyDocumentByHash', sha256Hash);
-            verified = true;
+            verified = !blockchainRecord.status || blockchainRecord.status === 'CURRENT';
         } catch { verified = false; }
         if (fs.existsSync(req.file.path)) fs.unlinkSync(req.file.path);
         res.json({
             success: true, data: {
                 verified, sha256Hash, message: verified
                     ? '✅ Document is authentic — hash matches blockchain record'
-                    : '⚠️ Document not found on blockchain — may be tampered or not registered',
+                    : blockchainRecord
+                        ? `⚠️ Document was registered but is now ${blockchainRecord.status}`
+                        : '⚠️ Document not found on blockchain — may be tampered or not registered',
                 blockchainRecord: blockchainRecord || null
             }
         });
@@ -314,12 +316,16 @@ const verifyByHash = async (req, res) => {
         const { hash } = req.params;
         await gateway.connect(req.user || {});
         let verified = false, blockchainRecord = null;
-        try { blockchainRecord = await gateway.evaluateTransaction('VerifyDocumentByHash', hash); verified = true; }
-        catch { verified = false; }
+        try {
+            blockchainRecord = await gateway.evaluateTransaction('VerifyDocumentByHash', hash);
+            verified = !blockchainRecord.status || blockchainRecord.status === 'CURRENT';
+        } catch { verified = false; }
         res.json({
             success: true, data: {
                 verified, sha256Hash: hash,
-                message: verified ? '✅ Hash found — document is authentic' : '⚠️ Hash not found on blockchain',
+                message: verified ? '✅ Hash found — document is authentic'
+                    : blockchainRecord ? `⚠️ Hash belongs to a ${blockchainRecord.status} document`
+                        : '⚠️ Hash not found on blockchain',
                 blockchainRecord: blockchainRecord || null
             }
         });
@@ -334,7 +340,7 @@ const getStudentDocuments = async (req, res) => {
     try {
         const { studentId } = req.params;
         await gateway.connect(req.user);
-        const result = await gateway.evaluateTransaction('GetDocumentsByStudent', studentId);
+        const result = await gateway.evaluateAllPages('GetDocumentsByStudent', studentId);
         res.json({ success: true, data: Array.isArray(result) ? result : [] });
     } catch {
         res.json({ success: true, data: [] });
@@ -131,7 +131,7 @@ class FacultyController {
             let students = [];
             try {
                 await gateway.connect(req.user);
-                const result = await gateway.evaluateTransaction('GetStudentsByFaculty', facultyId, facultyDepartment);
+                const result = await gateway.evaluateAllPages('GetStudentsByFaculty', facultyId, facultyDepartment);
                 if (result && typeof result === 'object') {
                     students = result;
                 }
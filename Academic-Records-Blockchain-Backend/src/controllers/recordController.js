@@ -1,3 +1,4 @@
+const crypto = require('crypto');
 const FabricGateway = require('../fabricGateway');
 const logger = require('../utils/logger');
 
@@ -21,14 +22,17 @@ class RecordController {
             await gateway.connect(req.user);
 
             // Chaincode signature: CreateAcademicRecord(recordID, rollNumber, semester int, year, department, coursesJSON string)
-            await gateway.submitTransaction(
+            // Grades are kept off the public ledger until finalization, so courses travel as transient data.
+            // The random salt keeps the hash of the private record from being matched against guessed grades.
+            await gateway.submitTransactionWithTransient(
                 'CreateAcademicRecord',
+                { courses: JSON.stringify(courses), salt: crypto.randomBytes(32) },
                 recordID,
                 rollNumber,
                 semester.toString(),
                 year,
                 department,
-                JSON.stringify(courses)
+                ''
             );
 
             logger.info(`Academic record created: ${recordID}`);
@@ -108,6 +112,43 @@ class RecordController {
         }
     }
 
+    // Supersede academic record so a corrected record can be created for its semester
+    static async supersedeAcademicRecord(req, res) {
+        const gateway = new FabricGateway();
+
+        try {
+            const { recordID } = req.params;
+            const { reason } = req.body;
+
+            if (!reason) {
+                return res.status(400).json({
+                    success: false,
+                    message: 'Supersession reason is required'
+                });
+            }
+
+            await gateway.connect(req.user);
+
+            const result = await gateway.submitTransaction('SupersedeAcademicRecord', recordID, reason);
+
+            logger.info(`Academic record superseded: ${recordID}`);
+
+            res.status(200).json({
+                success: true,
+                message: 'Academic record superseded successfully',
+                data: result
+            });
+        } catch (error) {
+            logger.error(`Error superseding academic record: ${error.message}`);
+            res.status(500).json({
+                success: false,
+                message: error.message
+            });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
     // Get student records
     static async getStudentRecords(req, res) {
         const gateway = new FabricGateway();
@@ -119,7 +160,7 @@ class RecordController {
             await gateway.connect(req.user);
 
             // Use GetStudentHistory from chaincode
-            const result = await gateway.evaluateTransaction('GetStudentHistory', rollNumber);
+            const result = await gateway.evaluateAllPages('GetStudentHistory', rollNumber);
 
             res.status(200).json({
                 success: true,
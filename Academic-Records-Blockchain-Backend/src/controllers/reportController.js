@@ -40,7 +40,7 @@ const getDashboardSummary = async (req, res) => {
         };
 
         // Students
-        const students = await fetchStat('GetAllStudents');
+        const students = await gateway.evaluateAllPages('GetAllStudents').catch(() => null);
         summary.students.total = Array.isArray(students) ? students.length :
             (students?.students?.length || students?.count || 0);
 
@@ -108,8 +108,7 @@ const exportStudentsCSV = async (req, res) => {
         await gateway.connect(req.user);
         let students = [];
         try {
-            const result = JSON.parse((await gateway.evaluateTransaction('GetAllStudents')).toString());
-            students = Array.isArray(result) ? result : (result?.students || []);
+            students = await gateway.evaluateAllPages('GetAllStudents');
         } catch { }
 
         const headers = ['rollNumber', 'name', 'department', 'degree', 'batchYear', 'cgpa', 'status'];
@@ -185,8 +184,8 @@ const blockchainExplorer = async (req, res) => {
             }
         } else if (type === 'students') {
             try {
-                const result = JSON.parse((await gateway.evaluateTransaction('GetAllStudents')).toString());
-                data = (Array.isArray(result) ? result : (result?.students || [])).map(s => ({ ...s, _type: 'STUDENT' }));
+                const result = await gateway.evaluateAllPages('GetAllStudents');
+                data = result.map(s => ({ ...s, _type: 'STUDENT' }));
             } catch { }
         }
 
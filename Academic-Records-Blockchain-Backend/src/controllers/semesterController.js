@@ -97,7 +97,7 @@ const getStudentSemesters = async (req, res) => {
         const { studentId } = req.params;
 
         await gateway.connect(req.user);
-        const result = await gateway.evaluateTransaction('GetSemesterRegistrationsByStudent', studentId);
+        const result = await gateway.evaluateAllPages('GetSemesterRegistrationsByStudent', studentId);
 
         res.json({
             success: true,
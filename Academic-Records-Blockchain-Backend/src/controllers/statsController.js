@@ -21,8 +21,7 @@ class StatsController {
 
             // Get all students to count total and active
             try {
-                const allStudentsResult = await gateway.evaluateTransaction('GetAllStudents');
-                const students = allStudentsResult || [];
+                const students = await gateway.evaluateAllPages('GetAllStudents');
 
                 totalStudents = students.length;
                 activeStudents = students.filter(s => s.status === 'ACTIVE').length;
@@ -30,8 +29,8 @@ class StatsController {
                 // Count certificates for each student (optimized with error handling)
                 const certPromises = students.map(async (student) => {
                     try {
-                        const certs = await gateway.evaluateTransaction('GetCertificatesByStudent', student.rollNumber || student.studentId);
-                        return Array.isArray(certs) ? certs.length : 0;
+                        const certs = await gateway.evaluateAllPages('GetCertificatesByStudent', student.rollNumber || student.studentId);
+                        return certs.length;
                     } catch (error) {
                         // Student has no certificates
                         return 0;
@@ -46,15 +45,11 @@ class StatsController {
             }
 
             // Get pending records
-            for (const status of ['DRAFT', 'SUBMITTED', 'FACULTY_APPROVED', 'HOD_APPROVED', 'EXAM_LOCKED', 'DEAN_APPROVED']) {
-                try {
-                    const pendingResult = await gateway.evaluateTransaction('QueryPendingRecords', status, '100');
-                    const pendingData = pendingResult || {};
-                    const pendingRecords = pendingData.records || [];
-                    pendingRecordsCount += pendingRecords.length;
-                } catch (error) {
-                    logger.warn(`Error fetching pending records for ${status}: ${error.message}`);
-                }
+            try {
+                const pendingRecords = await gateway.evaluateAllPages('QueryPendingRecords');
+                pendingRecordsCount = pendingRecords.length;
+            } catch (error) {
+                logger.warn(`Error fetching pending records: ${error.message}`);
             }
 
             const stats = {
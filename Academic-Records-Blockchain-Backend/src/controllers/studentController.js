@@ -11,15 +11,15 @@ class StudentController {
         try {
             // Support both formats: legacy (firstName/lastName) and new (name)
             let { rollNumber, name, department, enrollmentYear, email, category, admissionCategory } = req.body;
-            const { firstName, lastName, status, contactNumber, address } = req.body;
+            const { firstName, lastName, contactNumber, address } = req.body;
 
             // Construct name if firstName and lastName provided (legacy format)
             if (!name && firstName) {
                 name = lastName ? `${firstName} ${lastName}` : firstName;
             }
 
-            // Use admissionCategory, or fall back to status, or default to 'GENERAL'
-            const finalCategory = admissionCategory || status || category || 'GENERAL';
+            // Use admissionCategory, or fall back to the legacy category field, or default to 'GEN'
+            const finalCategory = admissionCategory || category || 'GEN';
 
             // Validate required fields
             if (!rollNumber || !name || !department || !enrollmentYear || !email) {
@@ -29,6 +29,15 @@ class StudentController {
                 });
             }
 
+            // Aadhaar is required except for foreign nationals, so duplicate enrollments are caught
+            const aadhaar = (req.body.privateData || {}).aadhaar || req.body.aadhaar;
+            if (!aadhaar && finalCategory.toUpperCase() !== 'FOREIGN_NATIONAL') {
+                return res.status(400).json({
+                    success: false,
+                    message: 'Missing required field: aadhaar (required unless admissionCategory is FOREIGN_NATIONAL)'
+                });
+            }
+
             // Connect as admin or authorized user
             await gateway.connect(req.user);
 
@@ -36,10 +45,11 @@ class StudentController {
             // Support both formats: privateData object or direct fields
             const privateData = req.body.privateData || {};
             const transientData = {
-                aadhaarHash: privateData.aadhaarHash || 'HASH-' + rollNumber, // Default if not provided
                 phone: privateData.phone || contactNumber || '0000000000', // Use contactNumber if provided
                 personalEmail: privateData.personalEmail || email // Use email as fallback
             };
+            // Raw Aadhaar number; the chaincode stores only its keyed HMAC
+            if (aadhaar) transientData.aadhaar = String(aadhaar);
 
             // Submit transaction to blockchain
             const result = await gateway.submitTransactionWithTransient(
@@ -61,7 +71,7 @@ class StudentController {
                 const caClient = FabricCAClient.getCAClientForRole('student');
                 await caClient.registerUser(
                     rollNumber,   // wallet key = rollNumber (same as username after createStudentUser)
-                    { role: 'student', department, email },
+                    { role: 'student', department, email, rollNumber },
                     'client',
                     ''
                 );
@@ -172,7 +182,7 @@ class StudentController {
 
         try {
             const { rollNumber } = req.params;
-            const { newStatus, reason } = req.body;
+            const { newStatus, reason, supportingDocId } = req.body;
             const userId = req.user.userId;
 
             await gateway.connect(req.user);
@@ -181,7 +191,8 @@ class StudentController {
                 'UpdateStudentStatus',
                 rollNumber,
                 newStatus,
-                reason || ''
+                reason || '',
+                supportingDocId || ''
             );
 
             logger.info(`Student status updated: ${rollNumber} -> ${newStatus}`);
@@ -208,15 +219,26 @@ class StudentController {
 
         try {
             const { rollNumber } = req.params;
-            const { newDepartment } = req.body;
+            const { newDepartment, effectiveSemester, approvalDocId, reason } = req.body;
             const userId = req.user.userId;
 
+            if (!newDepartment || !effectiveSemester || !approvalDocId || !reason) {
+                return res.status(400).json({
+                    success: false,
+                    message: 'Missing required fields: newDepartment, effectiveSemester, approvalDocId, reason'
+                });
+            }
+
             await gateway.connect(req.user);
 
+            // Branch change: records from effectiveSemester onwards move to the new department
             const result = await gateway.submitTransaction(
-                'UpdateStudentDepartment',
+                'BranchChange',
                 rollNumber,
-                newDepartment
+                newDepartment,
+                effectiveSemester.toString(),
+                approvalDocId,
+                reason
             );
 
             logger.info(`Student department updated: ${rollNumber} -> ${newDepartment}`);
@@ -237,6 +259,48 @@ class StudentController {
         }
     }
 
+    // Correct student profile fields (name, enrollment year, admission category)
+    static async updateStudentProfile(req, res) {
+        const gateway = new FabricGateway();
+
+        try {
+            const { rollNumber } = req.params;
+            const { updates, reason } = req.body;
+
+            if (!updates || typeof updates !== 'object' || Object.keys(updates).length === 0 || !reason) {
+                return res.status(400).json({
+                    success: false,
+                    message: 'Missing required fields: updates, reason'
+                });
+            }
+
+            await gateway.connect(req.user);
+
+            const result = await gateway.submitTransaction(
+                'UpdateStudentProfile',
+                rollNumber,
+                JSON.stringify(updates),
+                reason
+            );
+
+            logger.info(`Student profile updated: ${rollNumber} (${Object.keys(updates).join(', ')})`);
+
+            res.status(200).json({
+                success: true,
+                message: 'Student profile updated successfully',
+                data: result
+            });
+        } catch (error) {
+            logger.error(`Error updating student profile: ${error.message}`);
+            res.status(500).json({
+                success: false,
+                message: error.message
+            });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
     // Update student contact info
     static async updateStudentContactInfo(req, res) {
         const gateway = new FabricGateway();
@@ -370,6 +434,35 @@ class StudentController {
         }
     }
 
+    // Recompute and reconcile stored SGPA/CGPA values
+    static async recomputeStudentCGPA(req, res) {
+        const gateway = new FabricGateway();
+
+        try {
+            const { rollNumber } = req.params;
+
+            await gateway.connect(req.user);
+
+            const result = await gateway.submitTransaction('RecomputeStudentCGPA', rollNumber);
+
+            logger.info(`Student CGPA recomputed: ${rollNumber} (${result.oldCGPA} -> ${result.newCGPA})`);
+
+            res.status(200).json({
+                success: true,
+                message: result.changed ? 'Student CGPA reconciled' : 'Student CGPA already up to date',
+                data: result
+            });
+        } catch (error) {
+            logger.error(`Error recomputing student CGPA: ${error.message}`);
+            res.status(500).json({
+                success: false,
+                message: error.message
+            });
+        } finally {
+            await gateway.disconnect();
+        }
+    }
+
     // Get students by enrollment year
     static async getStudentsByYear(req, res) {
         const gateway = new FabricGateway();
@@ -412,7 +505,7 @@ class StudentController {
 
             await gateway.connect(req.user);
 
-            const result = await gateway.evaluateTransaction('GetAllStudents');
+            const result = await gateway.evaluateAllPages('GetAllStudents');
 
             res.status(200).json({
                 success: true,
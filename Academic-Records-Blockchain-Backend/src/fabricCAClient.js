@@ -183,6 +183,10 @@ class FabricCAClient {
             if (attributes.email) {
                 registerRequest.attrs.push({ name: 'email', value: attributes.email, ecert: true });
             }
+            if (attributes.rollNumber) {
+                // Chaincode uses rollNumber to limit student identities to their own records
+                registerRequest.attrs.push({ name: 'rollNumber', value: attributes.rollNumber, ecert: true });
+            }
 
             let secret;
             try {
@@ -8,6 +8,16 @@ const {
     getWalletPath
 } = require('./config/app.config');
 
+// Unreleased grades live in per-department collections held only by NITWarangalMSP peers,
+// so transactions that read or write them must run on those peers
+const GRADES_COLLECTION_ORG = 'NITWarangalMSP';
+const GRADES_FUNCTIONS = new Set([
+    'CreateAcademicRecord', 'GetAcademicRecord', 'GetStudentHistory', 'ApproveAcademicRecord',
+    'SubmitForApproval', 'FacultyApprove', 'HODApprove', 'ExamSectionApprove', 'DeanAcademicApprove',
+    'DACApprove', 'RejectRecord', 'SupersedeAcademicRecord', 'RecomputeStudentCGPA', 'BranchChange',
+    'QueryPendingRecords', 'QueryRecordsBySemester', 'QueryRecordsByStatus', 'QueryRecordsByDepartment'
+]);
+
 class FabricGateway {
     constructor() {
         this.channelName = APP_CONFIG.fabric.channelName;
@@ -93,6 +103,21 @@ class FabricGateway {
         }
     }
 
+    /**
+     * Create a transaction, pinned to the peers holding the grades collections when it
+     * touches unreleased grades.
+     */
+    createTransaction(functionName) {
+        const transaction = this.contract.createTransaction(functionName);
+        if (GRADES_FUNCTIONS.has(functionName)) {
+            const endorsers = this.network.getChannel().getEndorsers(GRADES_COLLECTION_ORG);
+            if (endorsers.length > 0) {
+                transaction.setEndorsingPeers(endorsers);
+            }
+        }
+        return transaction;
+    }
+
     async submitTransaction(functionName, ...args) {
         try {
             if (!this.contract) {
@@ -101,7 +126,7 @@ class FabricGateway {
 
             logger.info(`Submitting transaction: ${functionName} with args: ${JSON.stringify(args)}`);
 
-            const result = await this.contract.submitTransaction(functionName, ...args);
+            const result = await this.createTransaction(functionName).submit(...args);
 
             logger.info(`Transaction ${functionName} submitted successfully`);
 
@@ -124,7 +149,7 @@ class FabricGateway {
             }
 
             logger.info(`Evaluating transaction: ${functionName} with args: ${JSON.stringify(args)}`);
-            const result = await this.contract.evaluateTransaction(functionName, ...args);
+            const result = await this.createTransaction(functionName).evaluate(...args);
 
             logger.info(`Transaction ${functionName} evaluated successfully`);
 
@@ -140,6 +165,21 @@ class FabricGateway {
         }
     }
 
+    /**
+     * Evaluate a paginated list query page by page and return every record.
+     * The bookmark and page size are appended after the given arguments.
+     */
+    async evaluateAllPages(functionName, ...args) {
+        const records = [];
+        let bookmark = '';
+        do {
+            const page = await this.evaluateTransaction(functionName, ...args, bookmark, '100');
+            records.push(...(page?.records || []));
+            bookmark = page?.hasMore ? page.bookmark : '';
+        } while (bookmark);
+        return records;
+    }
+
     async submitTransactionWithTransient(functionName, transientData, ...args) {
         try {
             if (!this.contract) {
@@ -154,7 +194,7 @@ class FabricGateway {
 
             logger.info(`Submitting transaction with transient data: ${functionName}`);
 
-            const transaction = this.contract.createTransaction(functionName);
+            const transaction = this.createTransaction(functionName);
             transaction.setTransient(transientMap);
 
             const result = await transaction.submit(...args);
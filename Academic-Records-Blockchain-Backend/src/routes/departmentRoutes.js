@@ -16,6 +16,15 @@ router.post('/courses/create', authenticateToken, requireRole('department', 'adm
 // Department students routes
 router.get('/:departmentId/students', authenticateToken, requireRole('department', 'admin'), DepartmentController.getStudentsByDepartment);
 
+// Department result analytics (?semester=3&year=2024-25&top=10)
+router.get('/:departmentId/analytics', authenticateToken, requireRole('department', 'admin'), DepartmentController.getResultAnalytics);
+
+// Merit lists
+router.post('/:departmentId/merit-lists', authenticateToken, requireRole('admin'), DepartmentController.generateMeritList);
+router.get('/:departmentId/merit-lists', authenticateToken, requireRole('department', 'admin'), DepartmentController.getMeritLists);
+router.get('/merit-lists/:meritListId', authenticateToken, requireRole('department', 'admin'), DepartmentController.getMeritList);
+router.get('/merit-lists/:meritListId/verify', authenticateToken, DepartmentController.verifyMeritList);
+
 // Department record management routes
 router.post('/records', authenticateToken, requireRole('department', 'admin'), RecordController.createAcademicRecord);
 router.get('/records/:departmentId', authenticateToken, requireRole('department', 'admin'), DepartmentController.getRecordsByDepartment);
@@ -15,6 +15,9 @@ router.get('/:recordID', RecordController.getAcademicRecord);
 // Approve academic record (Admin only)
 router.post('/:recordID/approve', requireRole('admin'), RecordController.approveAcademicRecord);
 
+// Supersede academic record (Admin only)
+router.post('/:recordID/supersede', requireRole('admin'), RecordController.supersedeAcademicRecord);
+
 // Get student records (All authenticated users)
 router.get('/student/:rollNumber', RecordController.getStudentRecords);
 
@@ -24,6 +24,9 @@ router.patch('/:rollNumber/status', requireRole('admin'), StudentController.upda
 // Update student department (Admin only)
 router.patch('/:rollNumber/department', requireRole('admin'), StudentController.updateStudentDepartment);
 
+// Correct student profile fields (Admin only)
+router.patch('/:rollNumber/profile', requireRole('admin'), StudentController.updateStudentProfile);
+
 // Update student contact info (Admin and Faculty with department check)
 router.patch('/:rollNumber/contact', requireRole('admin', 'faculty'), enforceDepartmentAccess, StudentController.updateStudentContactInfo);
 
@@ -39,4 +42,7 @@ router.get('/year/:year', requireRole('admin', 'faculty'), StudentController.get
 // Get student CGPA (All authenticated users)
 router.get('/:rollNumber/cgpa', StudentController.getStudentCGPA);
 
+// Recompute student SGPA/CGPA (Admin only)
+router.post('/:rollNumber/cgpa/recompute', requireRole('admin'), StudentController.recomputeStudentCGPA);
+
 module.exports = router;
@@ -84,7 +84,7 @@ async function seed() {
         const year = student.enrollmentYear
             ? student.enrollmentYear.toString()
             : new Date().getFullYear().toString();
-        const category = student.admissionCategory || 'GENERAL';
+        const category = student.admissionCategory || 'GEN';
 
         process.stdout.write(`  • ${rollNumber} (${name}) … `);
 
@@ -98,10 +98,11 @@ async function seed() {
         // 2. Submit CreateStudent transaction
         try {
             const transientData = {
-                aadhaarHash: Buffer.from(`HASH-${rollNumber}`),
                 phone: Buffer.from(student.phone || '0000000000'),
                 personalEmail: Buffer.from(email),
             };
+            // Required by the chaincode except for FOREIGN_NATIONAL students
+            if (student.aadhaar) transientData.aadhaar = Buffer.from(String(student.aadhaar));
 
             await gateway.submitTransactionWithTransient(
                 'CreateStudent',
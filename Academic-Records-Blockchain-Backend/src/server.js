@@ -299,16 +299,17 @@ async function autoSeedBlockchain() {
             } catch (_) {
                 try {
                     const transientData = {
-                        aadhaarHash: Buffer.from(`HASH-${roll}`),
                         phone: Buffer.from(s.phone || '0000000000'),
                         personalEmail: Buffer.from(s.email || `${roll}@student.nitw.ac.in`),
                     };
+                    // Required by the chaincode except for FOREIGN_NATIONAL students
+                    if (s.aadhaar) transientData.aadhaar = Buffer.from(String(s.aadhaar));
                     await gateway.submitTransactionWithTransient(
                         'CreateStudent', transientData,
                         roll, s.name || roll, s.department || 'CSE',
                         (s.enrollmentYear || new Date().getFullYear()).toString(),
                         s.email || `${roll}@student.nitw.ac.in`,
-                        s.admissionCategory || 'GENERAL'
+                        s.admissionCategory || 'GEN'
                     );
                     created++;
                 } catch (e) {
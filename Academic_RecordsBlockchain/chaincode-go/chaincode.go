@@ -1,20 +1,35 @@
 package main
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
 const (
 	// studentPrivateCollection is the name of the private data collection for sensitive student data
 	studentPrivateCollection = "studentPrivateCollection"
+
+	// identityKeyName is the studentPrivateCollection key holding the secret used to HMAC identity numbers
+	identityKeyName = "identity~hmackey"
+
+	// admissionCategoriesKey holds the configured admission categories; unset means defaultAdmissionCategories
+	admissionCategoriesKey = "config~admissioncategories"
+
+	// foreignNationalCategory is the only admission category enrolled without an Aadhaar number
+	foreignNationalCategory = "FOREIGN_NATIONAL"
 )
 
 // SmartContract provides functions for managing academic records
@@ -24,6 +39,7 @@ type SmartContract struct {
 
 // Student represents the public part of a student's record
 type Student struct {
+	DocType            string    `json:"docType"`
 	StudentID          string    `json:"studentId"`
 	Name               string    `json:"name"`
 	Department         string    `json:"department"`
@@ -38,6 +54,7 @@ type Student struct {
 	CreatedAt          time.Time `json:"createdAt"`
 	ModifiedBy         string    `json:"modifiedBy"`
 	ModifiedAt         time.Time `json:"modifiedAt"`
+	StatusChangedAt    time.Time `json:"statusChangedAt,omitempty"` // Start of the retention period for private data
 }
 
 // StudentPrivateDetails represents the private part of a student's record
@@ -45,11 +62,12 @@ type StudentPrivateDetails struct {
 	StudentID     string `json:"studentId"`
 	Phone         string `json:"phone"`
 	PersonalEmail string `json:"personalEmail"`
-	AadhaarHash   string `json:"aadhaarHash"` // SHA256 hash of Aadhaar
+	AadhaarHash   string `json:"aadhaarHash"` // HMAC-SHA256 of Aadhaar, keyed with the identity secret
 }
 
 // Department represents an academic department
 type Department struct {
+	DocType        string    `json:"docType"`
 	DepartmentID   string    `json:"departmentId"`   // e.g., "CSE", "ECE", "ME"
 	DepartmentName string    `json:"departmentName"` // e.g., "Computer Science and Engineering"
 	HOD            string    `json:"hod"`            // Head of Department name
@@ -63,6 +81,7 @@ type Department struct {
 
 // CourseOffering represents a course offered by department with many-to-many relationship
 type CourseOffering struct {
+	DocType      string    `json:"docType"`
 	OfferingID   string    `json:"offeringId"`   // Unique ID: dept-course-semester-year
 	DepartmentID string    `json:"departmentId"` // Department offering the course
 	CourseCode   string    `json:"courseCode"`   // e.g., "CS301"
@@ -82,16 +101,18 @@ type Course struct {
 	CourseCode string  `json:"courseCode"`
 	CourseName string  `json:"courseName"`
 	Credits    float64 `json:"credits"`    // 0.5-6 credits
-	Grade      string  `json:"grade"`      // S, A, B, C, D, P, U, R
+	Grade      string  `json:"grade"`      // a grade of the record's grading scheme
 	Department string  `json:"department"` // Changed from FacultyID to Department
 }
 
 // AcademicRecord represents semester academic records (Enhanced)
 type AcademicRecord struct {
+	DocType       string    `json:"docType"`
 	RecordID      string    `json:"recordId"`
 	StudentID     string    `json:"studentId"`
 	Department    string    `json:"department"` // For department-level access control
 	Semester      int       `json:"semester"`
+	AcademicYear  string    `json:"academicYear"` // e.g., "2024-25"
 	Courses       []Course  `json:"courses"`
 	TotalCredits  float64   `json:"totalCredits"`
 	SGPA          float64   `json:"sgpa"`
@@ -101,10 +122,31 @@ type AcademicRecord struct {
 	ApprovedBy    string    `json:"approvedBy"`    // Admin who approved
 	Status        string    `json:"status"`        // DRAFT, SUBMITTED, APPROVED
 	RejectionNote string    `json:"rejectionNote"` // If sent back for corrections
+
+	// A student has one record per semester. A corrected record replaces a SUPERSEDED one
+	// and names it in Supersedes; the superseded record names its replacement in SupersededBy.
+	Supersedes       string `json:"supersedes,omitempty"`
+	SupersededBy     string `json:"supersededBy,omitempty"`
+	SupersessionNote string `json:"supersessionNote,omitempty"`
+
+	// The grading scheme the record was graded under ("" for records graded before schemes
+	// were configurable), the credits its SGPA is weighted by and the credits it earns
+	GradingSchemeID string  `json:"gradingSchemeId,omitempty"`
+	GPACredits      float64 `json:"gpaCredits"`
+	EarnedCredits   float64 `json:"earnedCredits"`
+
+	// Until a record is released its grades live in the department's private collection
+	// and public state only carries a stub with the hash of the private record. The private
+	// record carries a random salt from the client so that the hash cannot be matched against
+	// guessed grades.
+	GradesCollection string `json:"gradesCollection,omitempty"`
+	GradesHash       string `json:"gradesHash,omitempty"`
+	GradesSalt       string `json:"gradesSalt,omitempty"`
 }
 
 // Certificate represents a certificate issued to a student (Enhanced)
 type Certificate struct {
+	DocType          string    `json:"docType"`
 	CertificateID    string    `json:"certificateId"`
 	StudentID        string    `json:"studentId"`
 	Type             string    `json:"type"` // DEGREE, TRANSCRIPT, PROVISIONAL, BONAFIDE, MIGRATION, CHARACTER, STUDY_CONDUCT
@@ -179,12 +221,50 @@ const (
 	RecordSemesterKey = "record~semester"
 	RecordStatusKey   = "record~status"
 	RecordDeptKey     = "record~department"
+	RecordDeptYearKey = "record~dept~year"
 	CertStudentKey    = "cert~student"
 	DepartmentAllKey  = "department~all"
 	CourseOfferingKey = "course~offering"
 	CourseDeptKey     = "course~dept"
+
+	// Private index in studentPrivateCollection: student~aadhaar~{AadhaarHash} -> rollNumber
+	StudentAadhaarKey = "student~aadhaar"
+
+	// Public tombstone left when a student's private details are purged: student~purge~{RollNumber}
+	StudentPurgeKey = "student~purge"
+
+	// Status transitions of a student: student~statushistory~{RollNumber}
+	StudentStatusHistoryKey = "student~statushistory"
+
+	// Department transfers of a student: student~branchhistory~{RollNumber}
+	StudentBranchHistoryKey = "student~branchhistory"
+
+	// Corrections to a student's public profile: student~profilehistory~{RollNumber}
+	StudentProfileHistoryKey = "student~profilehistory"
+
+	// Asset types. Each primary asset is stored under the composite key {docType}~{ID}
+	// and carries its type in the docType field.
+	DocTypeStudent        = "student"
+	DocTypeRecord         = "record"
+	DocTypeCertificate    = "certificate"
+	DocTypeDepartment     = "department"
+	DocTypeCourseOffering = "courseOffering"
+	DocTypeDocument       = "document"
+	DocTypeRegistration   = "registration"
+	DocTypeMeritList      = "meritList"
+	DocTypeGradingScheme  = "gradingScheme"
 )
 
+// assetTypes lists the asset types in the order they are scanned
+var assetTypes = []string{
+	DocTypeStudent, DocTypeRecord, DocTypeCertificate, DocTypeDepartment,
+	DocTypeCourseOffering, DocTypeDocument, DocTypeRegistration, DocTypeMeritList, DocTypeGradingScheme,
+}
+
+// PrivateDataRetentionYears is how long a withdrawn or cancelled student's private details
+// are kept after the status change before they may be purged
+const PrivateDataRetentionYears = 3
+
 // Validation helper functions
 
 // validateEmail checks if email is valid NIT Warangal student email
@@ -199,15 +279,25 @@ func validateEmail(email string) error {
 	return nil
 }
 
-// validateGrade checks if grade is valid
-func validateGrade(grade string) error {
-	validGrades := []string{GradeS, GradeA, GradeB, GradeC, GradeD, GradeP, GradeU, GradeR}
-	for _, vg := range validGrades {
-		if grade == vg {
-			return nil
-		}
+// validateAadhaar checks that an Aadhaar number is 12 digits and does not start with 0 or 1
+func validateAadhaar(aadhaar string) error {
+	aadhaarRegex := regexp.MustCompile(`^[2-9][0-9]{11}$`)
+	if !aadhaarRegex.MatchString(aadhaar) {
+		return fmt.Errorf("invalid aadhaar number format")
+	}
+	return nil
+}
+
+// validateGrade checks if grade is valid under a grading scheme
+func validateGrade(scheme *GradingScheme, grade string) error {
+	if _, ok := scheme.lookup(grade); ok {
+		return nil
 	}
-	return fmt.Errorf("invalid grade '%s'. Valid grades: S, A, B, C, D, P, U, R", grade)
+	validGrades := make([]string, 0, len(scheme.Grades))
+	for _, definition := range scheme.Grades {
+		validGrades = append(validGrades, definition.Grade)
+	}
+	return fmt.Errorf("invalid grade '%s'. Valid grades under %s: %s", grade, scheme.SchemeID, strings.Join(validGrades, ", "))
 }
 
 // validateCredits checks if credit value is valid
@@ -226,6 +316,17 @@ func validateSemester(semester int) error {
 	return nil
 }
 
+// academicYearPattern accepts a calendar year ("2024") or an academic year ("2024-25")
+var academicYearPattern = regexp.MustCompile(`^[0-9]{4}(-[0-9]{2})?$`)
+
+// validateAcademicYear checks if an academic year label is valid
+func validateAcademicYear(year string) error {
+	if !academicYearPattern.MatchString(year) {
+		return fmt.Errorf("invalid academic year %q (expected YYYY or YYYY-YY)", year)
+	}
+	return nil
+}
+
 // validateStatus checks if status is valid
 func validateStatus(status string) error {
 	validStatuses := []string{StatusActive, StatusGraduated, StatusWithdrawn, StatusCancelled, StatusTemporaryWithdrawal}
@@ -265,15 +366,48 @@ func checkClientAttribute(ctx contractapi.TransactionContextInterface, attribute
 
 // Access control helper functions
 
+// callerIdentity describes the invoking client as seen by the access control helpers
+type callerIdentity struct {
+	MSPID      string
+	Role       string
+	RollNumber string
+}
+
+// isStudent reports whether the caller is a student. Students enroll under NITWarangalMSP
+// with role=student and a rollNumber attribute, and never hold the institute's admin rights.
+func (c *callerIdentity) isStudent() bool {
+	return c.Role == RoleStudent || c.RollNumber != ""
+}
+
+// getCaller reads the caller's MSP ID and its role and rollNumber attributes
+func getCaller(ctx contractapi.TransactionContextInterface) (*callerIdentity, error) {
+	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client MSP ID: %v", err)
+	}
+
+	role, _, err := ctx.GetClientIdentity().GetAttributeValue("role")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client attribute 'role': %w", err)
+	}
+	rollNumber, _, err := ctx.GetClientIdentity().GetAttributeValue("rollNumber")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client attribute 'rollNumber': %w", err)
+	}
+
+	return &callerIdentity{MSPID: clientMSPID, Role: role, RollNumber: rollNumber}, nil
+}
+
 // checkMSPAccess verifies if caller is from allowed organization
 func checkMSPAccess(ctx contractapi.TransactionContextInterface, allowedMSPs ...string) error {
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	caller, err := getCaller(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get client MSP ID: %v", err)
+		return err
 	}
 
 	for _, msp := range allowedMSPs {
-		if clientMSPID == msp {
+		// Students share NITWarangalMSP but are not institute administration
+		if caller.MSPID == msp && !(msp == NITWarangalMSP && caller.isStudent()) {
 			return nil
 		}
 	}
@@ -282,18 +416,18 @@ func checkMSPAccess(ctx contractapi.TransactionContextInterface, allowedMSPs ...
 
 // checkDepartmentAccess verifies if caller can access department-specific data
 func checkDepartmentAccess(ctx contractapi.TransactionContextInterface, department string) error {
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	caller, err := getCaller(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get client MSP ID: %v", err)
+		return err
 	}
 
-	// NITWarangalMSP has access to all departments
-	if clientMSPID == NITWarangalMSP {
+	// NITWarangalMSP administration has access to all departments
+	if caller.MSPID == NITWarangalMSP && !caller.isStudent() {
 		return nil
 	}
 
 	// DepartmentsMSP can only access their own department via an attribute
-	if clientMSPID == DepartmentsMSP {
+	if caller.MSPID == DepartmentsMSP {
 		err := checkClientAttribute(ctx, "department", department)
 		if err != nil {
 			return fmt.Errorf("department access check failed: %w", err)
@@ -304,6 +438,71 @@ func checkDepartmentAccess(ctx contractapi.TransactionContextInterface, departme
 	return fmt.Errorf("unauthorized")
 }
 
+// authorizeRead is the single access check for reads of student-owned data:
+//   - students may read only data carrying their own rollNumber attribute
+//   - NITWarangalMSP administration may read everything
+//   - DepartmentsMSP may read data owned by its department (checkDepartmentAccess)
+//   - VerifiersMSP needs an active consent from the student
+//
+// department is the department that owns the data being read.
+func (s *SmartContract) authorizeRead(ctx contractapi.TransactionContextInterface, rollNumber, department string) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case caller.isStudent():
+		if caller.RollNumber == "" {
+			return fmt.Errorf("unauthorized: student identity has no rollNumber attribute")
+		}
+		if caller.RollNumber != rollNumber {
+			return fmt.Errorf("unauthorized: students can only read their own records")
+		}
+		return nil
+	case caller.MSPID == NITWarangalMSP:
+		return nil
+	case caller.MSPID == DepartmentsMSP:
+		return checkDepartmentAccess(ctx, department)
+	case caller.MSPID == VerifiersMSP:
+		requesterID, err := getClientCommonName(ctx)
+		if err != nil {
+			return err
+		}
+		consented, err := s.CheckConsent(ctx, rollNumber, requesterID)
+		if err != nil {
+			return fmt.Errorf("failed to check consent: %w", err)
+		}
+		if !consented {
+			return fmt.Errorf("unauthorized: no active consent from student %s for %s", rollNumber, requesterID)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("unauthorized")
+}
+
+// authorizeStudentRead applies authorizeRead to a student's own record
+func (s *SmartContract) authorizeStudentRead(ctx contractapi.TransactionContextInterface, student *Student) error {
+	return s.authorizeRead(ctx, student.RollNumber, student.Department)
+}
+
+// authorizeStudentReadByID applies authorizeRead to data that only carries a student ID
+// (certificates, documents, registrations, consents), using the student's department
+func (s *SmartContract) authorizeStudentReadByID(ctx contractapi.TransactionContextInterface, rollNumber string) error {
+	student, err := s.readStudent(ctx, rollNumber)
+	if err != nil {
+		return err
+	}
+	return s.authorizeStudentRead(ctx, student)
+}
+
+// authorizeRecordRead applies authorizeRead to an academic record, which is owned by the
+// department that submitted it
+func (s *SmartContract) authorizeRecordRead(ctx contractapi.TransactionContextInterface, record *AcademicRecord) error {
+	return s.authorizeRead(ctx, record.StudentID, record.Department)
+}
+
 // InitLedger initializes the ledger with sample data
 func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface) error {
 	fmt.Println("Initializing NIT Warangal Academic Records Blockchain - Production Version")
@@ -321,12 +520,6 @@ func (s *SmartContract) InitLedger(ctx contractapi.TransactionContextInterface)
 func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterface,
 	rollNumber, name, department string, enrollmentYear int, email, admissionCategory string) error {
 
-	// Access Control: Only NITWarangalMSP can create students
-	err := checkMSPAccess(ctx, NITWarangalMSP)
-	if err != nil {
-		return err
-	}
-
 	// Normalize department to uppercase
 	department = strings.ToUpper(department)
 
@@ -336,9 +529,17 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 		return fmt.Errorf("failed to get transient map: %w", err)
 	}
 
-	aadhaarHash, ok := transientMap["aadhaarHash"]
-	if !ok {
-		return fmt.Errorf("aadhaarHash must be provided in transient data")
+	// The raw Aadhaar number is required except for foreign nationals, who have none; only its
+	// keyed HMAC is stored
+	if _, ok := transientMap["aadhaarHash"]; ok {
+		return fmt.Errorf("aadhaarHash is no longer accepted, pass the aadhaar number in transient data")
+	}
+	aadhaarHash := ""
+	if aadhaar, ok := transientMap["aadhaar"]; ok && len(aadhaar) > 0 {
+		aadhaarHash, err = identityHMAC(ctx, string(aadhaar))
+		if err != nil {
+			return err
+		}
 	}
 	phone, ok := transientMap["phone"]
 	if !ok {
@@ -380,6 +581,22 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 		return fmt.Errorf("student with roll number %s already exists", rollNumber)
 	}
 
+	// The same person must not be enrolled twice under different roll numbers
+	var aadhaarKey string
+	if aadhaarHash != "" {
+		aadhaarKey, err = ctx.GetStub().CreateCompositeKey(StudentAadhaarKey, []string{aadhaarHash})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key for aadhaar hash: %w", err)
+		}
+		existingHash, err := ctx.GetStub().GetPrivateDataHash(studentPrivateCollection, aadhaarKey)
+		if err != nil {
+			return fmt.Errorf("failed to check aadhaar hash: %w", err)
+		}
+		if existingHash != nil {
+			return fmt.Errorf("a student with the same aadhaar number is already enrolled")
+		}
+	}
+
 	// Get transaction timestamp
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
@@ -393,7 +610,17 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 		return fmt.Errorf("failed to get client ID: %w", err)
 	}
 
+	admissionCategory, err = resolveAdmissionCategory(ctx, admissionCategory)
+	if err != nil {
+		return err
+	}
+	// Without a hash there is nothing to deduplicate the enrollment against
+	if aadhaarHash == "" && admissionCategory != foreignNationalCategory {
+		return fmt.Errorf("aadhaar must be provided in transient data for admission category %s", admissionCategory)
+	}
+
 	student := Student{
+		DocType:            DocTypeStudent,
 		StudentID:          rollNumber, // Using rollNumber as studentID
 		Name:               name,
 		Department:         department,
@@ -416,7 +643,7 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 	}
 
 	// Store public data
-	err = ctx.GetStub().PutState(rollNumber, studentJSON)
+	err = putAssetState(ctx, DocTypeStudent, rollNumber, studentJSON)
 	if err != nil {
 		return fmt.Errorf("failed to put public student data: %w", err)
 	}
@@ -424,7 +651,7 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 	// Store private data
 	privateDetails := StudentPrivateDetails{
 		StudentID:     rollNumber,
-		AadhaarHash:   string(aadhaarHash),
+		AadhaarHash:   aadhaarHash,
 		Phone:         string(phone),
 		PersonalEmail: string(personalEmail),
 	}
@@ -436,6 +663,12 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 	if err != nil {
 		return fmt.Errorf("failed to put private student data: %w", err)
 	}
+	if aadhaarKey != "" {
+		err = ctx.GetStub().PutPrivateData(studentPrivateCollection, aadhaarKey, []byte(rollNumber))
+		if err != nil {
+			return fmt.Errorf("failed to put private aadhaar index: %w", err)
+		}
+	}
 
 	// Create composite keys for efficient querying
 	// 1. student~department~rollNumber (for department-wise queries)
@@ -443,7 +676,7 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 	if err != nil {
 		return fmt.Errorf("failed to create composite key for department: %w", err)
 	}
-	err = ctx.GetStub().PutState(deptKey, studentJSON)
+	err = ctx.GetStub().PutState(deptKey, []byte{0x00})
 	if err != nil {
 		return fmt.Errorf("failed to put state for department key: %w", err)
 	}
@@ -453,7 +686,7 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 	if err != nil {
 		return fmt.Errorf("failed to create composite key for year: %w", err)
 	}
-	err = ctx.GetStub().PutState(yearKey, studentJSON)
+	err = ctx.GetStub().PutState(yearKey, []byte{0x00})
 	if err != nil {
 		return fmt.Errorf("failed to put state for year key: %w", err)
 	}
@@ -463,7 +696,7 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 	if err != nil {
 		return fmt.Errorf("failed to create composite key for status: %w", err)
 	}
-	err = ctx.GetStub().PutState(statusKey, studentJSON)
+	err = ctx.GetStub().PutState(statusKey, []byte{0x00})
 	if err != nil {
 		return err
 	}
@@ -498,11 +731,16 @@ func (s *SmartContract) CreateStudent(ctx contractapi.TransactionContextInterfac
 
 // GetStudentPrivateDetails retrieves the private details of a student
 func (s *SmartContract) GetStudentPrivateDetails(ctx contractapi.TransactionContextInterface, rollNumber string) (*StudentPrivateDetails, error) {
-	// Access Control: Only NITWarangalMSP can get private details
-	err := checkMSPAccess(ctx, NITWarangalMSP)
+	// Access Control: NITWarangalMSP administration, or the student themself
+	caller, err := getCaller(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if !caller.isStudent() || caller.RollNumber != rollNumber {
+		if err := checkMSPAccess(ctx, NITWarangalMSP); err != nil {
+			return nil, err
+		}
+	}
 
 	privateDetailsJSON, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, rollNumber)
 	if err != nil {
@@ -521,393 +759,479 @@ func (s *SmartContract) GetStudentPrivateDetails(ctx contractapi.TransactionCont
 	return &privateDetails, nil
 }
 
-// GetStudent retrieves a student record (Enhanced with department-level access control)
-func (s *SmartContract) GetStudent(ctx contractapi.TransactionContextInterface, rollNumber string) (*Student, error) {
-	studentJSON, err := ctx.GetStub().GetState(rollNumber)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read from world state: %v", err)
-	}
-	if studentJSON == nil {
-		return nil, fmt.Errorf("student %s does not exist", rollNumber)
+// normalizeAadhaarHash canonicalises a hex hash so that case differences cannot bypass deduplication
+func normalizeAadhaarHash(hash string) string {
+	return strings.ToLower(strings.TrimSpace(hash))
+}
+
+// identityHMAC returns the hex HMAC-SHA256 of an Aadhaar number under the identity secret.
+// A plain hash would be trivially brute-forced over the 12-digit Aadhaar space.
+func identityHMAC(ctx contractapi.TransactionContextInterface, aadhaar string) (string, error) {
+	aadhaar = strings.ReplaceAll(strings.TrimSpace(aadhaar), " ", "")
+	if err := validateAadhaar(aadhaar); err != nil {
+		return "", err
 	}
 
-	var student Student
-	err = json.Unmarshal(studentJSON, &student)
+	key, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, identityKeyName)
 	if err != nil {
-		return nil, err
+		return "", fmt.Errorf("failed to read identity hash key: %w", err)
+	}
+	if key == nil {
+		return "", fmt.Errorf("identity hash key has not been set")
 	}
 
-	return &student, nil
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(aadhaar))
+	return hex.EncodeToString(mac.Sum(nil)), nil
 }
 
-// UpdateStudentStatus updates the status of a student (Enhanced with RBAC and approval)
-func (s *SmartContract) UpdateStudentStatus(ctx contractapi.TransactionContextInterface,
-	rollNumber, newStatus, reason string) error {
-
-	// Access Control: Only NITWarangalMSP can update status
-	err := checkMSPAccess(ctx, NITWarangalMSP)
+// SetIdentityHashKey stores the secret used to HMAC Aadhaar numbers. The key is read from the
+// transient field "identityHashKey" and can only be set once, since changing it would orphan
+// every stored hash.
+func (s *SmartContract) SetIdentityHashKey(ctx contractapi.TransactionContextInterface) error {
+	transientMap, err := ctx.GetStub().GetTransient()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get transient map: %w", err)
 	}
-
-	// Validate new status
-	err = validateStatus(newStatus)
-	if err != nil {
-		return err
+	key, ok := transientMap["identityHashKey"]
+	if !ok || len(key) < 32 {
+		return fmt.Errorf("identityHashKey of at least 32 bytes must be provided in transient data")
 	}
 
-	student, err := s.GetStudent(ctx, rollNumber)
+	existing, err := ctx.GetStub().GetPrivateDataHash(studentPrivateCollection, identityKeyName)
 	if err != nil {
-		return err
-	}
-
-	oldStatus := student.Status
-
-	// Critical status changes (CANCELLED, WITHDRAWN) require reason
-	if (newStatus == StatusCancelled || newStatus == StatusWithdrawn) && reason == "" {
-		return fmt.Errorf("reason required for status change to %s", newStatus)
+		return fmt.Errorf("failed to check identity hash key: %w", err)
 	}
-
-	// Get transaction timestamp
-	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
-	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	if existing != nil {
+		return fmt.Errorf("identity hash key is already set")
 	}
-	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Get modifier ID
-	clientID, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		return fmt.Errorf("failed to get client identity: %v", err)
+	if err := ctx.GetStub().PutPrivateData(studentPrivateCollection, identityKeyName, key); err != nil {
+		return fmt.Errorf("failed to store identity hash key: %w", err)
 	}
+	return nil
+}
 
-	student.Status = newStatus
-	student.ModifiedBy = clientID
-	student.ModifiedAt = timestamp
-
-	studentJSON, err := json.Marshal(student)
-	if err != nil {
-		return err
+// VerifyStudentIdentity checks a candidate Aadhaar number, passed in the transient field
+// "aadhaar", against the one enrolled for the student. Only the match result is returned.
+func (s *SmartContract) VerifyStudentIdentity(ctx contractapi.TransactionContextInterface, rollNumber string) (bool, error) {
+	if err := s.authorizeStudentReadByID(ctx, rollNumber); err != nil {
+		return false, err
 	}
 
-	// Update main record
-	err = ctx.GetStub().PutState(rollNumber, studentJSON)
+	transientMap, err := ctx.GetStub().GetTransient()
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to get transient map: %w", err)
 	}
-
-	// Update status composite key
-	// Remove old status key
-	oldStatusKey, err := ctx.GetStub().CreateCompositeKey(StudentStatusKey, []string{oldStatus, rollNumber})
-	if err == nil {
-		ctx.GetStub().DelState(oldStatusKey)
+	candidate, ok := transientMap["aadhaar"]
+	if !ok || len(candidate) == 0 {
+		return false, fmt.Errorf("aadhaar must be provided in transient data")
 	}
-
-	// Add new status key
-	newStatusKey, err := ctx.GetStub().CreateCompositeKey(StudentStatusKey, []string{newStatus, rollNumber})
+	candidateHash, err := identityHMAC(ctx, string(candidate))
 	if err != nil {
-		return err
+		return false, err
 	}
-	err = ctx.GetStub().PutState(newStatusKey, studentJSON)
+
+	privateDetailsJSON, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, rollNumber)
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to read private details for student %s: %w", rollNumber, err)
 	}
-
-	// Emit status change event
-	eventPayload := map[string]interface{}{
-		"rollNumber": rollNumber,
-		"oldStatus":  oldStatus,
-		"newStatus":  newStatus,
-		"reason":     reason,
-		"modifiedBy": clientID,
-		"modifiedAt": timestamp,
+	if privateDetailsJSON == nil {
+		return false, nil
 	}
-	eventJSON, _ := json.Marshal(eventPayload)
-	err = ctx.GetStub().SetEvent("StudentStatusChanged", eventJSON)
-	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
+	var privateDetails StudentPrivateDetails
+	if err := json.Unmarshal(privateDetailsJSON, &privateDetails); err != nil {
+		return false, fmt.Errorf("failed to unmarshal private details: %w", err)
 	}
 
-	return nil
+	return hmac.Equal([]byte(candidateHash), []byte(privateDetails.AadhaarHash)), nil
 }
 
-// UpdateStudentContactInfo updates modifiable contact information in the private data collection
-func (s *SmartContract) UpdateStudentContactInfo(ctx contractapi.TransactionContextInterface, rollNumber string) error {
-
-	// Access Control: Only NITWarangalMSP can update
-	err := checkMSPAccess(ctx, NITWarangalMSP)
+// RehashStudentIdentity brings a student's stored Aadhaar hash under the identity key. The
+// Aadhaar number is read from the transient field "aadhaar". A hash stored before keyed hashing
+// is a plain SHA-256, which the number must match; a student enrolled without a number gets
+// it recorded. Either way the duplicate index is updated and checked. The legacy index entry is
+// purged, so its unkeyed hash leaves the private data history; the earlier versions of the
+// student's private details still carry it until PurgeStudentPrivateDetails purges them.
+func (s *SmartContract) RehashStudentIdentity(ctx contractapi.TransactionContextInterface, rollNumber string) error {
+	transientMap, err := ctx.GetStub().GetTransient()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get transient map: %w", err)
 	}
-
-	// Check if student exists
-	student, err := s.GetStudent(ctx, rollNumber)
+	aadhaar, ok := transientMap["aadhaar"]
+	if !ok || len(aadhaar) == 0 {
+		return fmt.Errorf("aadhaar must be provided in transient data")
+	}
+	aadhaarHash, err := identityHMAC(ctx, string(aadhaar))
 	if err != nil {
 		return err
 	}
 
-	// Get private data from transient map
-	transientMap, err := ctx.GetStub().GetTransient()
+	privateDetailsJSON, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, rollNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get transient map: %w", err)
+		return fmt.Errorf("failed to read private details for student %s: %w", rollNumber, err)
 	}
-
-	// Fetch existing private details
-	privateDetails, err := s.GetStudentPrivateDetails(ctx, rollNumber)
-	if err != nil {
-		return err
+	if privateDetailsJSON == nil {
+		return fmt.Errorf("private details for student %s do not exist", rollNumber)
+	}
+	var privateDetails StudentPrivateDetails
+	if err := json.Unmarshal(privateDetailsJSON, &privateDetails); err != nil {
+		return fmt.Errorf("failed to unmarshal private details: %w", err)
 	}
 
-	// Update fields if new values are provided in transient data
-	if phone, ok := transientMap["phone"]; ok {
-		privateDetails.Phone = string(phone)
+	oldHash := normalizeAadhaarHash(privateDetails.AadhaarHash)
+	if oldHash == aadhaarHash {
+		return fmt.Errorf("aadhaar hash of student %s is already keyed", rollNumber)
 	}
-	if personalEmail, ok := transientMap["personalEmail"]; ok {
-		privateDetails.PersonalEmail = string(personalEmail)
+	if oldHash != "" {
+		normalized := strings.ReplaceAll(strings.TrimSpace(string(aadhaar)), " ", "")
+		legacyHash := sha256.Sum256([]byte(normalized))
+		if hex.EncodeToString(legacyHash[:]) != oldHash {
+			return fmt.Errorf("aadhaar number does not match the one enrolled for student %s", rollNumber)
+		}
 	}
 
-	// Get modifier ID and timestamp
-	clientID, err := ctx.GetClientIdentity().GetID()
+	aadhaarKey, err := ctx.GetStub().CreateCompositeKey(StudentAadhaarKey, []string{aadhaarHash})
 	if err != nil {
-		return fmt.Errorf("failed to get client ID: %w", err)
+		return fmt.Errorf("failed to create composite key for aadhaar hash: %w", err)
 	}
-	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	existingHash, err := ctx.GetStub().GetPrivateDataHash(studentPrivateCollection, aadhaarKey)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+		return fmt.Errorf("failed to check aadhaar hash: %w", err)
+	}
+	if existingHash != nil {
+		return fmt.Errorf("a student with the same aadhaar number is already enrolled")
 	}
-	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Update public student record's modification timestamp
-	student.ModifiedBy = clientID
-	student.ModifiedAt = timestamp
-	studentJSON, err := json.Marshal(student)
-	if err != nil {
-		return fmt.Errorf("failed to marshal student for modification tracking: %w", err)
+	if oldHash != "" {
+		oldKey, err := ctx.GetStub().CreateCompositeKey(StudentAadhaarKey, []string{oldHash})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key for aadhaar hash: %w", err)
+		}
+		if err := ctx.GetStub().PurgePrivateData(studentPrivateCollection, oldKey); err != nil {
+			return fmt.Errorf("failed to purge old aadhaar index: %w", err)
+		}
 	}
-	err = ctx.GetStub().PutState(rollNumber, studentJSON)
-	if err != nil {
-		return fmt.Errorf("failed to update student modification timestamp: %w", err)
+	if err := ctx.GetStub().PutPrivateData(studentPrivateCollection, aadhaarKey, []byte(rollNumber)); err != nil {
+		return fmt.Errorf("failed to put private aadhaar index: %w", err)
 	}
 
-	// Save updated private details
-	privateDetailsJSON, err := json.Marshal(privateDetails)
+	privateDetails.AadhaarHash = aadhaarHash
+	privateDetailsJSON, err = json.Marshal(privateDetails)
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated private details: %w", err)
+		return fmt.Errorf("failed to marshal private details: %w", err)
+	}
+	if err := ctx.GetStub().PutPrivateData(studentPrivateCollection, rollNumber, privateDetailsJSON); err != nil {
+		return fmt.Errorf("failed to put private student data: %w", err)
 	}
 
-	err = ctx.GetStub().PutPrivateData(studentPrivateCollection, rollNumber, privateDetailsJSON)
-	if err != nil {
-		return fmt.Errorf("failed to put updated private details: %w", err)
+	eventPayload := map[string]interface{}{
+		"rollNumber": rollNumber,
+		"legacy":     oldHash != "",
+		"txId":       ctx.GetStub().GetTxID(),
 	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("StudentIdentityRehashed", eventJSON)
 
 	return nil
 }
 
-// UpdateStudentDepartment updates a student's department with proper composite key cleanup
-func (s *SmartContract) UpdateStudentDepartment(ctx contractapi.TransactionContextInterface, rollNumber, newDepartment string) error {
-	// Access Control: Only NITWarangalMSP can update department
-	err := checkMSPAccess(ctx, NITWarangalMSP)
+// ResolveAadhaarHash returns the roll number enrolled under an Aadhaar hash. The stored hash is
+// read from the transient field "aadhaarHash", or computed from a raw "aadhaar" number, so it is
+// not recorded in the proposal.
+func (s *SmartContract) ResolveAadhaarHash(ctx contractapi.TransactionContextInterface) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to get transient map: %w", err)
 	}
-
-	// Get existing student record directly without access control checks
-	studentJSON, err := ctx.GetStub().GetState(rollNumber)
-	if err != nil {
-		return fmt.Errorf("failed to read student: %v", err)
+	aadhaarHash := normalizeAadhaarHash(string(transientMap["aadhaarHash"]))
+	if aadhaar, ok := transientMap["aadhaar"]; ok && len(aadhaar) > 0 {
+		aadhaarHash, err = identityHMAC(ctx, string(aadhaar))
+		if err != nil {
+			return "", err
+		}
 	}
-	if studentJSON == nil {
-		return fmt.Errorf("student %s does not exist", rollNumber)
+	if aadhaarHash == "" {
+		return "", fmt.Errorf("aadhaarHash or aadhaar must be provided in transient data")
 	}
 
-	var student Student
-	err = json.Unmarshal(studentJSON, &student)
+	aadhaarKey, err := ctx.GetStub().CreateCompositeKey(StudentAadhaarKey, []string{aadhaarHash})
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal student: %v", err)
+		return "", fmt.Errorf("failed to create composite key for aadhaar hash: %w", err)
 	}
-
-	oldDepartment := student.Department
-
-	// If department hasn't changed, no need to proceed
-	if oldDepartment == newDepartment {
-		return fmt.Errorf("student is already in department %s", newDepartment)
+	rollNumber, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, aadhaarKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read aadhaar index: %w", err)
+	}
+	if rollNumber == nil {
+		return "", fmt.Errorf("no student is enrolled with this aadhaar hash")
 	}
 
-	// Get transaction timestamp and client ID
-	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
-	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %v", err)
-	}
-	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+	return string(rollNumber), nil
+}
 
-	clientID, err := ctx.GetClientIdentity().GetID()
+// GetStudent retrieves a student record (Enhanced with department-level access control)
+func (s *SmartContract) GetStudent(ctx contractapi.TransactionContextInterface, rollNumber string) (*Student, error) {
+	student, err := s.readStudent(ctx, rollNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get client identity: %v", err)
+		return nil, err
 	}
-
-	// Update student record
-	student.Department = newDepartment
-	student.ModifiedBy = clientID
-	student.ModifiedAt = timestamp
-
-	updatedStudentJSON, err := json.Marshal(student)
-	if err != nil {
-		return fmt.Errorf("failed to marshal student: %w", err)
+	if err := s.authorizeStudentRead(ctx, student); err != nil {
+		return nil, err
 	}
+	return student, nil
+}
 
-	// Update main record
-	err = ctx.GetStub().PutState(rollNumber, updatedStudentJSON)
+// readStudent loads a student record without access control, for use inside transactions
+// that have already authorized the caller
+func (s *SmartContract) readStudent(ctx contractapi.TransactionContextInterface, rollNumber string) (*Student, error) {
+	studentJSON, err := getAssetState(ctx, DocTypeStudent, rollNumber)
 	if err != nil {
-		return fmt.Errorf("failed to update student record: %w", err)
+		return nil, fmt.Errorf("failed to read from world state: %v", err)
 	}
-
-	// Update composite keys
-	// 1. Remove old department composite key
-	oldDeptKey, err := ctx.GetStub().CreateCompositeKey(StudentDeptKey, []string{oldDepartment, rollNumber})
-	if err == nil {
-		err = ctx.GetStub().DelState(oldDeptKey)
-		if err != nil {
-			return fmt.Errorf("failed to delete old department key: %w", err)
-		}
+	if studentJSON == nil {
+		return nil, fmt.Errorf("student %s does not exist", rollNumber)
 	}
 
-	// 2. Add new department composite key
-	newDeptKey, err := ctx.GetStub().CreateCompositeKey(StudentDeptKey, []string{newDepartment, rollNumber})
-	if err != nil {
-		return fmt.Errorf("failed to create new department key: %w", err)
-	}
-	err = ctx.GetStub().PutState(newDeptKey, updatedStudentJSON)
+	var student Student
+	err = json.Unmarshal(studentJSON, &student)
 	if err != nil {
-		return fmt.Errorf("failed to put new department key: %w", err)
+		return nil, err
 	}
 
-	// Emit department change event
-	eventPayload := map[string]interface{}{
-		"rollNumber":    rollNumber,
-		"oldDepartment": oldDepartment,
-		"newDepartment": newDepartment,
-		"modifiedBy":    clientID,
-		"modifiedAt":    timestamp,
-	}
-	eventJSON, _ := json.Marshal(eventPayload)
-	err = ctx.GetStub().SetEvent("StudentDepartmentChanged", eventJSON)
-	if err != nil {
-		return fmt.Errorf("failed to set event: %v", err)
-	}
+	return &student, nil
+}
 
-	return nil
+// StatusChange is one entry in a student's status history
+type StatusChange struct {
+	FromStatus      string    `json:"fromStatus"`
+	ToStatus        string    `json:"toStatus"`
+	Reason          string    `json:"reason"`
+	SupportingDocID string    `json:"supportingDocId,omitempty"`
+	RejoinSemester  int       `json:"rejoinSemester,omitempty"` // Set by ReadmitStudent
+	ChangedBy       string    `json:"changedBy"`
+	ChangedAt       time.Time `json:"changedAt"`
+	TxID            string    `json:"txId"`
 }
 
-// StudentExists checks if a student exists
-func (s *SmartContract) StudentExists(ctx contractapi.TransactionContextInterface, studentID string) (bool, error) {
-	studentJSON, err := ctx.GetStub().GetState(studentID)
-	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %w", err)
-	}
-	return studentJSON != nil, nil
+// statusTransitionRule lists what a status transition must be justified with
+type statusTransitionRule struct {
+	ReasonRequired   bool
+	DocumentRequired bool
 }
 
-// recordExists checks if an academic record exists.
-func (s *SmartContract) recordExists(ctx contractapi.TransactionContextInterface, recordID string) (bool, error) {
-	recordJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil {
-		return false, fmt.Errorf("failed to read from world state: %w", err)
-	}
-	return recordJSON != nil, nil
+// studentStatusTransitions is the student lifecycle. GRADUATED, WITHDRAWN and CANCELLED are
+// final. TEMPORARY_WITHDRAWAL -> ACTIVE is not listed: it goes through ReadmitStudent.
+var studentStatusTransitions = map[string]map[string]statusTransitionRule{
+	StatusActive: {
+		StatusTemporaryWithdrawal: {ReasonRequired: true, DocumentRequired: true},
+		StatusWithdrawn:           {ReasonRequired: true, DocumentRequired: true},
+		StatusCancelled:           {ReasonRequired: true, DocumentRequired: true},
+		StatusGraduated:           {ReasonRequired: false, DocumentRequired: false},
+	},
+	StatusTemporaryWithdrawal: {
+		StatusWithdrawn: {ReasonRequired: true, DocumentRequired: true},
+		StatusCancelled: {ReasonRequired: true, DocumentRequired: true},
+	},
 }
 
-// CreateAcademicRecord creates a new academic record (Enhanced with validation and access control)
-func (s *SmartContract) CreateAcademicRecord(ctx contractapi.TransactionContextInterface,
-	recordID, rollNumber string, semester int, year string, department string, coursesJSON string) error {
+// studentStatusRule lists the student statuses an operation accepts. Statuses in
+// CompletedSemesterOnly are accepted only for a semester the student registered for before the
+// last status change, e.g. results for a semester completed before withdrawal.
+type studentStatusRule struct {
+	Allowed               []string
+	CompletedSemesterOnly []string
+}
 
-	// Access Control: Only DepartmentsMSP or NITWarangalMSP can create records
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get client MSP ID: %w", err)
-	}
-	if clientMSPID != DepartmentsMSP && clientMSPID != NITWarangalMSP {
-		return fmt.Errorf("unauthorized: only %s or %s can create academic records", DepartmentsMSP, NITWarangalMSP)
+// studentOperationRules gates academic operations on the student's status. Certificates are
+// keyed by type as "IssueCertificate:<TYPE>".
+var studentOperationRules = map[string]studentStatusRule{
+	"CreateAcademicRecord": {
+		Allowed:               []string{StatusActive},
+		CompletedSemesterOnly: []string{StatusTemporaryWithdrawal, StatusWithdrawn, StatusGraduated},
+	},
+	"SubmitForApproval": {
+		Allowed:               []string{StatusActive},
+		CompletedSemesterOnly: []string{StatusTemporaryWithdrawal, StatusWithdrawn, StatusGraduated},
+	},
+	"RegisterForSemester": {
+		Allowed: []string{StatusActive},
+	},
+	"BranchChange": {
+		Allowed: []string{StatusActive},
+	},
+	"IssueCertificate:" + CertDegree:       {Allowed: []string{StatusGraduated}},
+	"IssueCertificate:" + CertProvisional:  {Allowed: []string{StatusActive, StatusGraduated}},
+	"IssueCertificate:" + CertTranscript:   {Allowed: []string{StatusActive, StatusGraduated, StatusWithdrawn, StatusTemporaryWithdrawal}},
+	"IssueCertificate:" + CertBonafide:     {Allowed: []string{StatusActive}},
+	"IssueCertificate:" + CertStudyConduct: {Allowed: []string{StatusActive, StatusGraduated}},
+	"IssueCertificate:" + CertMigration:    {Allowed: []string{StatusGraduated, StatusWithdrawn}},
+	"IssueCertificate:" + CertCharacter:    {Allowed: []string{StatusActive, StatusGraduated, StatusWithdrawn, StatusTemporaryWithdrawal}},
+}
+
+// checkStudentStatusFor verifies that the student's status permits the operation. semester is
+// the semester the operation concerns, or 0 when it is not semester-specific.
+func (s *SmartContract) checkStudentStatusFor(ctx contractapi.TransactionContextInterface, operation string, student *Student, semester int) error {
+	rule, ok := studentOperationRules[operation]
+	if !ok {
+		return fmt.Errorf("no student status rule defined for %s", operation)
 	}
 
-	// If created by a department, verify the department attribute matches the record's department
-	if clientMSPID == DepartmentsMSP {
-		err := checkClientAttribute(ctx, "department", department)
+	for _, status := range rule.Allowed {
+		if student.Status == status {
+			return nil
+		}
+	}
+	for _, status := range rule.CompletedSemesterOnly {
+		if student.Status != status || semester == 0 {
+			continue
+		}
+		completed, err := s.semesterRegisteredBefore(ctx, student.RollNumber, semester, student.StatusChangedAt)
 		if err != nil {
-			return fmt.Errorf("department user cannot create a record for another department: %w", err)
+			return err
+		}
+		if completed {
+			return nil
 		}
+		return fmt.Errorf("%s is not allowed for %s student %s except for semesters registered before the status change", operation, student.Status, student.RollNumber)
 	}
 
-	// Check if record already exists
-	exists, err := s.recordExists(ctx, recordID)
+	return fmt.Errorf("%s is not allowed for %s student %s", operation, student.Status, student.RollNumber)
+}
+
+// semesterRegisteredBefore reports whether the student had a registration for the semester,
+// not dropped, made before the given time. A zero time (status changed before it was tracked)
+// accepts any such registration.
+func (s *SmartContract) semesterRegisteredBefore(ctx contractapi.TransactionContextInterface, rollNumber string, semester int, before time.Time) (bool, error) {
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(SemesterRegKey, []string{rollNumber, fmt.Sprintf("%d", semester)})
 	if err != nil {
-		return err
+		return false, fmt.Errorf("failed to query semester registrations: %w", err)
 	}
-	if exists {
-		return fmt.Errorf("academic record %s already exists", recordID)
+	defer iter.Close()
+
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return false, err
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+		regJSON, err := getAssetState(ctx, DocTypeRegistration, parts[2])
+		if err != nil || regJSON == nil {
+			continue
+		}
+		var reg SemesterRegistration
+		if err := json.Unmarshal(regJSON, &reg); err != nil {
+			continue
+		}
+		if reg.Status == "DROPPED" {
+			continue
+		}
+		if before.IsZero() || reg.RegisteredAt.Before(before) {
+			return true, nil
+		}
 	}
+	return false, nil
+}
 
-	// Verify student exists
-	exists, err = s.StudentExists(ctx, rollNumber)
+// validateSupportingDocument checks that a document exists, belongs to the student and has
+// not been retracted
+func (s *SmartContract) validateSupportingDocument(ctx contractapi.TransactionContextInterface, rollNumber, docID string) error {
+	doc, err := s.readDocument(ctx, docID)
 	if err != nil {
-		return err
+		return fmt.Errorf("supporting document: %w", err)
 	}
-	if !exists {
-		return fmt.Errorf("student %s does not exist", rollNumber)
+	if doc.StudentID != rollNumber {
+		return fmt.Errorf("supporting document %s does not belong to student %s", docID, rollNumber)
 	}
+	if doc.Status == DocStatusRetracted {
+		return fmt.Errorf("supporting document %s has been retracted", docID)
+	}
+	return nil
+}
 
-	// Validate semester (1-8)
-	if err := validateSemester(semester); err != nil {
+// UpdateStudentStatus moves a student to a new status along the lifecycle in
+// studentStatusTransitions, with the reason and supporting document the transition requires
+func (s *SmartContract) UpdateStudentStatus(ctx contractapi.TransactionContextInterface,
+	rollNumber, newStatus, reason, supportingDocID string) error {
+
+	// Validate new status
+	err := validateStatus(newStatus)
+	if err != nil {
 		return err
 	}
 
-	var courses []Course
-	err = json.Unmarshal([]byte(coursesJSON), &courses)
+	student, err := s.readStudent(ctx, rollNumber)
 	if err != nil {
-		return fmt.Errorf("failed to parse courses: %v", err)
+		return err
 	}
 
-	if len(courses) == 0 {
-		return fmt.Errorf("at least one course is required")
+	if student.Status == StatusTemporaryWithdrawal && newStatus == StatusActive {
+		return fmt.Errorf("students on temporary withdrawal return to ACTIVE through ReadmitStudent")
 	}
-
-	// Validate each course and calculate total credits
-	totalCredits := 0.0
-	for i, course := range courses {
-		// Validate course code
-		if len(course.CourseCode) < 3 || len(course.CourseCode) > 20 {
-			return fmt.Errorf("course %d: invalid course code length (must be 3-20 characters)", i+1)
-		}
-
-		// Validate course name
-		if len(course.CourseName) < 3 || len(course.CourseName) > 100 {
-			return fmt.Errorf("course %d: invalid course name length (must be 3-100 characters)", i+1)
+	rule, ok := studentStatusTransitions[student.Status][newStatus]
+	if !ok {
+		return fmt.Errorf("invalid status transition from %s to %s", student.Status, newStatus)
+	}
+	if rule.ReasonRequired && reason == "" {
+		return fmt.Errorf("reason required for status change to %s", newStatus)
+	}
+	if rule.DocumentRequired && supportingDocID == "" {
+		return fmt.Errorf("supporting document required for status change to %s", newStatus)
+	}
+	if supportingDocID != "" {
+		if err := s.validateSupportingDocument(ctx, rollNumber, supportingDocID); err != nil {
+			return err
 		}
+	}
 
-		// Validate credits (0.5-6)
-		if err := validateCredits(course.Credits); err != nil {
-			return fmt.Errorf("course %d (%s): %v", i+1, course.CourseCode, err)
-		}
+	return s.changeStudentStatus(ctx, student, StatusChange{
+		ToStatus:        newStatus,
+		Reason:          reason,
+		SupportingDocID: supportingDocID,
+	})
+}
 
-		// Validate grade (S, A, B, C, D, P, U, R)
-		if err := validateGrade(course.Grade); err != nil {
-			return fmt.Errorf("course %d (%s): %v", i+1, course.CourseCode, err)
-		}
+// ReadmitStudent returns a student on temporary withdrawal to ACTIVE from the given semester
+func (s *SmartContract) ReadmitStudent(ctx contractapi.TransactionContextInterface,
+	rollNumber string, rejoinSemester int, reason, supportingDocID string) error {
 
-		totalCredits += course.Credits
+	student, err := s.readStudent(ctx, rollNumber)
+	if err != nil {
+		return err
 	}
-
-	// Validate total credits per semester (16-30)
-	if totalCredits < 16.0 || totalCredits > 30.0 {
-		return fmt.Errorf("total semester credits %.1f out of range (must be 16-30)", totalCredits)
+	if student.Status != StatusTemporaryWithdrawal {
+		return fmt.Errorf("only students on %s can be readmitted, current status: %s", StatusTemporaryWithdrawal, student.Status)
+	}
+	if err := validateSemester(rejoinSemester); err != nil {
+		return err
+	}
+	if reason == "" {
+		return fmt.Errorf("reason required for readmission")
+	}
+	if supportingDocID == "" {
+		return fmt.Errorf("supporting document required for readmission")
+	}
+	if err := s.validateSupportingDocument(ctx, rollNumber, supportingDocID); err != nil {
+		return err
 	}
 
-	// Calculate GPA for this semester
-	_, sgpa := calculateGrades(courses)
+	return s.changeStudentStatus(ctx, student, StatusChange{
+		ToStatus:        StatusActive,
+		Reason:          reason,
+		SupportingDocID: supportingDocID,
+		RejoinSemester:  rejoinSemester,
+	})
+}
 
-	clientID, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		return fmt.Errorf("failed to get client identity: %v", err)
-	}
+// changeStudentStatus applies a validated status change: it updates the student and its status
+// index, appends the change to the status history and emits StudentStatusChanged
+func (s *SmartContract) changeStudentStatus(ctx contractapi.TransactionContextInterface, student *Student, change StatusChange) error {
+	rollNumber := student.RollNumber
+	oldStatus := student.Status
+	newStatus := change.ToStatus
 
 	// Get transaction timestamp
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
@@ -916,1718 +1240,3477 @@ func (s *SmartContract) CreateAcademicRecord(ctx contractapi.TransactionContextI
 	}
 	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Create academic record with DRAFT status initially
-	record := AcademicRecord{
-		RecordID:      recordID,
-		StudentID:     rollNumber, // Using rollNumber as student identifier
-		Department:    department,
-		Semester:      semester,
-		Courses:       courses,
-		TotalCredits:  totalCredits,
-		SGPA:          sgpa,
-		CGPA:          0.0, // Will be calculated on approval
-		Timestamp:     timestamp,
-		SubmittedBy:   clientID,
-		Status:        StatusDraft,
-		ApprovedBy:    "",
-		RejectionNote: "", // Initialize to empty string
+	// Get modifier ID
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
 	}
 
-	recordJSONBytes, err := json.Marshal(record)
+	student.Status = newStatus
+	student.ModifiedBy = clientID
+	student.ModifiedAt = timestamp
+	student.StatusChangedAt = timestamp
+
+	studentJSON, err := json.Marshal(student)
 	if err != nil {
 		return err
 	}
 
-	// Store with primary key
-	err = ctx.GetStub().PutState(recordID, recordJSONBytes)
+	// Update main record
+	err = putAssetState(ctx, DocTypeStudent, rollNumber, studentJSON)
 	if err != nil {
 		return err
 	}
 
-	// Create composite keys for efficient querying
-	// 1. student~record
-	recordKey, err := ctx.GetStub().CreateCompositeKey(StudentRecordKey, []string{rollNumber, recordID})
-	if err != nil {
-		return fmt.Errorf("failed to create composite key for student record: %w", err)
-	}
-	err = ctx.GetStub().PutState(recordKey, []byte{0x00}) // Use a null byte as value
-	if err != nil {
-		return fmt.Errorf("failed to put state for student record key: %w", err)
+	// Update status composite key
+	// Remove old status key
+	oldStatusKey, err := ctx.GetStub().CreateCompositeKey(StudentStatusKey, []string{oldStatus, rollNumber})
+	if err == nil {
+		ctx.GetStub().DelState(oldStatusKey)
 	}
 
-	// 2. record~semester~{Semester}~{StudentID}~{RecordID}
-	semKey, err := ctx.GetStub().CreateCompositeKey(RecordSemesterKey, []string{fmt.Sprintf("%d", semester), rollNumber, recordID})
+	// Add new status key
+	newStatusKey, err := ctx.GetStub().CreateCompositeKey(StudentStatusKey, []string{newStatus, rollNumber})
 	if err != nil {
-		return fmt.Errorf("failed to create composite key for semester record: %w", err)
+		return err
 	}
-	err = ctx.GetStub().PutState(semKey, []byte{0x00}) // Use a null byte as value
+	err = ctx.GetStub().PutState(newStatusKey, []byte{0x00})
 	if err != nil {
-		return fmt.Errorf("failed to put state for semester record key: %w", err)
+		return err
 	}
 
-	// 3. record~status~{Status}~{StudentID}~{RecordID}
-	statusKey, err := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{StatusDraft, rollNumber, recordID})
+	// Append to status history
+	change.FromStatus = oldStatus
+	change.ChangedBy = clientID
+	change.ChangedAt = timestamp
+	change.TxID = ctx.GetStub().GetTxID()
+	history, err := s.readStatusHistory(ctx, rollNumber)
 	if err != nil {
-		return fmt.Errorf("failed to create composite key for status record: %w", err)
+		return err
 	}
-	err = ctx.GetStub().PutState(statusKey, []byte{0x00}) // Use a null byte as value
+	history = append(history, change)
+	historyJSON, err := json.Marshal(history)
 	if err != nil {
-		return fmt.Errorf("failed to put state for status record key: %w", err)
+		return fmt.Errorf("failed to marshal status history: %w", err)
 	}
-
-	// 4. record~department
-	deptKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptKey, []string{department, rollNumber, recordID})
+	historyKey, err := ctx.GetStub().CreateCompositeKey(StudentStatusHistoryKey, []string{rollNumber})
 	if err != nil {
-		return fmt.Errorf("failed to create composite key for department record: %w", err)
+		return fmt.Errorf("failed to create composite key for status history: %w", err)
 	}
-	err = ctx.GetStub().PutState(deptKey, []byte{0x00}) // Use a null byte as value
-	if err != nil {
-		return fmt.Errorf("failed to put state for department record key: %w", err)
+	if err := ctx.GetStub().PutState(historyKey, historyJSON); err != nil {
+		return fmt.Errorf("failed to put status history: %w", err)
 	}
 
-	// Emit event
+	// Emit status change event
 	eventPayload := map[string]interface{}{
-		"recordID":     recordID,
-		"rollNumber":   rollNumber,
-		"semester":     semester,
-		"year":         year,
-		"department":   department,
-		"coursesCount": len(courses),
-		"totalCredits": totalCredits,
-		"sgpa":         sgpa,
-		"status":       record.Status,
-		"submittedBy":  clientID,
-		"timestamp":    timestamp.Format("2006-01-02T15:04:05Z07:00"),
+		"rollNumber":      rollNumber,
+		"oldStatus":       oldStatus,
+		"newStatus":       newStatus,
+		"reason":          change.Reason,
+		"supportingDocId": change.SupportingDocID,
+		"rejoinSemester":  change.RejoinSemester,
+		"modifiedBy":      clientID,
+		"modifiedAt":      timestamp,
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	err = ctx.GetStub().SetEvent("StudentStatusChanged", eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
 	}
-	eventJSONBytes, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordCreated", eventJSONBytes)
 
 	return nil
 }
 
-// GetAcademicRecord retrieves an academic record (Enhanced with department access control)
-func (s *SmartContract) GetAcademicRecord(ctx contractapi.TransactionContextInterface, recordID string) (*AcademicRecord, error) {
-	recordJSON, err := ctx.GetStub().GetState(recordID)
+// readStatusHistory loads a student's status history without access control
+func (s *SmartContract) readStatusHistory(ctx contractapi.TransactionContextInterface, rollNumber string) ([]StatusChange, error) {
+	historyKey, err := ctx.GetStub().CreateCompositeKey(StudentStatusHistoryKey, []string{rollNumber})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read record: %v", err)
+		return nil, fmt.Errorf("failed to create composite key for status history: %w", err)
 	}
-	if recordJSON == nil {
-		return nil, fmt.Errorf("record %s does not exist", recordID)
+	historyJSON, err := ctx.GetStub().GetState(historyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status history: %w", err)
 	}
 
-	var record AcademicRecord
-	err = json.Unmarshal(recordJSON, &record)
-	if err != nil {
-		return nil, err
+	history := []StatusChange{}
+	if historyJSON != nil {
+		if err := json.Unmarshal(historyJSON, &history); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal status history: %w", err)
+		}
 	}
+	return history, nil
+}
 
-	// Access Control: Check department access for DepartmentsMSP
-	err = checkDepartmentAccess(ctx, record.Department)
-	if err != nil {
+// GetStudentStatusHistory returns every status transition of a student, oldest first
+func (s *SmartContract) GetStudentStatusHistory(ctx contractapi.TransactionContextInterface, rollNumber string) ([]StatusChange, error) {
+	if err := s.authorizeStudentReadByID(ctx, rollNumber); err != nil {
 		return nil, err
 	}
-
-	return &record, nil
+	return s.readStatusHistory(ctx, rollNumber)
 }
 
-// ApproveAcademicRecord approves an academic record and calculates CGPA (Enhanced with RBAC and workflow)
-func (s *SmartContract) ApproveAcademicRecord(ctx contractapi.TransactionContextInterface, recordID string) error {
-	// Access Control: Only NITWarangalMSP (Admin) can approve records
-	err := checkMSPAccess(ctx, NITWarangalMSP)
+// UpdateStudentContactInfo updates modifiable contact information in the private data collection
+func (s *SmartContract) UpdateStudentContactInfo(ctx contractapi.TransactionContextInterface, rollNumber string) error {
+
+	// Check if student exists
+	student, err := s.readStudent(ctx, rollNumber)
 	if err != nil {
 		return err
 	}
 
-	// Attribute Check: Check for 'role' of 'admin' (optional - MSP check is sufficient)
-	roleErr := checkClientAttribute(ctx, "role", "admin")
-	if roleErr != nil {
-		// If no role attribute, proceed anyway since MSP check passed
-		// This allows admin identity from wallet without explicit role attribute
-		fmt.Printf("Note: Client approved without role attribute (MSP authorization sufficient)\n")
+	// Get private data from transient map
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient map: %w", err)
 	}
 
-	// Get record
-	record, err := s.GetAcademicRecord(ctx, recordID)
+	// Fetch existing private details
+	privateDetails, err := s.GetStudentPrivateDetails(ctx, rollNumber)
 	if err != nil {
 		return err
 	}
 
-	// Check if already approved
-	if record.Status == RecordApproved {
-		return fmt.Errorf("record %s is already approved", recordID)
+	// Update fields if new values are provided in transient data
+	if phone, ok := transientMap["phone"]; ok {
+		privateDetails.Phone = string(phone)
 	}
-
-	// Record must be submitted before approval
-	if record.Status != RecordSubmitted && record.Status != RecordDraft {
-		return fmt.Errorf("cannot approve record with status %s", record.Status)
+	if personalEmail, ok := transientMap["personalEmail"]; ok {
+		privateDetails.PersonalEmail = string(personalEmail)
 	}
 
-	// Calculate CGPA based on all approved records for this student
-	// Include the current record being approved in the calculation
-	newCGPA, totalCredits, err := s.calculateCGPAIncludingCurrent(ctx, record.StudentID, record.Semester, record.SGPA, record.TotalCredits)
+	// Get modifier ID and timestamp
+	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return fmt.Errorf("failed to calculate CGPA: %w", err)
+		return fmt.Errorf("failed to get client ID: %w", err)
 	}
-	record.CGPA = newCGPA
-
-	// Update student's overall CGPA and total credits
-	student, err := s.GetStudent(ctx, record.StudentID)
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return fmt.Errorf("failed to get student for CGPA update: %w", err)
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
 	}
-	student.CurrentCGPA = newCGPA
-	student.TotalCreditsEarned = totalCredits
+	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	// Update public student record's modification timestamp
+	student.ModifiedBy = clientID
+	student.ModifiedAt = timestamp
 	studentJSON, err := json.Marshal(student)
 	if err != nil {
-		return fmt.Errorf("failed to marshal student for CGPA update: %w", err)
+		return fmt.Errorf("failed to marshal student for modification tracking: %w", err)
 	}
-	err = ctx.GetStub().PutState(student.RollNumber, studentJSON)
+	err = putAssetState(ctx, DocTypeStudent, rollNumber, studentJSON)
 	if err != nil {
-		return fmt.Errorf("failed to update student with new CGPA: %w", err)
+		return fmt.Errorf("failed to update student modification timestamp: %w", err)
 	}
 
-	// Get approver identity
-	approverID, err := ctx.GetClientIdentity().GetID()
+	// Save updated private details
+	privateDetailsJSON, err := json.Marshal(privateDetails)
 	if err != nil {
-		return fmt.Errorf("failed to get approver ID: %w", err)
+		return fmt.Errorf("failed to marshal updated private details: %w", err)
 	}
 
-	// Get transaction timestamp
-	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	err = ctx.GetStub().PutPrivateData(studentPrivateCollection, rollNumber, privateDetailsJSON)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+		return fmt.Errorf("failed to put updated private details: %w", err)
 	}
-	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-
-	// Update composite keys if status changed
-	oldStatus := record.Status
-	if oldStatus != RecordApproved {
-		// Remove old status key
-		oldStatusKey, err := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{oldStatus, record.StudentID, recordID})
-		if err != nil {
-			return fmt.Errorf("failed to create old status composite key for deletion: %w", err)
-		}
-		err = ctx.GetStub().DelState(oldStatusKey)
-		if err != nil {
-			return fmt.Errorf("failed to delete old status composite key: %w", err)
-		}
 
-		// Add new status key
-		newStatusKey, err := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{RecordApproved, record.StudentID, recordID})
-		if err != nil {
-			return fmt.Errorf("failed to create new status composite key: %w", err)
-		}
-		err = ctx.GetStub().PutState(newStatusKey, []byte{0x00})
-		if err != nil {
-			return fmt.Errorf("failed to put new status composite key: %w", err)
-		}
-	}
+	return nil
+}
 
-	// Update record
-	record.Status = RecordApproved
-	record.ApprovedBy = approverID
-	record.Timestamp = timestamp // Update timestamp to approval time
+// PurgeTombstone is the public record of a purge of a student's private details
+type PurgeTombstone struct {
+	RollNumber string    `json:"rollNumber"`
+	Collection string    `json:"collection"`
+	Fields     []string  `json:"fields"`
+	Documents  []string  `json:"documents,omitempty"` // IDs of the sensitive documents purged
+	Reason     string    `json:"reason"`
+	PurgedBy   string    `json:"purgedBy"`
+	PurgedAt   time.Time `json:"purgedAt"`
+	TxID       string    `json:"txId"`
+}
 
-	recordJSON, err := json.Marshal(record)
+// purgePrivateKey purges a studentPrivateCollection key if it holds a value
+func purgePrivateKey(ctx contractapi.TransactionContextInterface, key string) error {
+	hash, err := ctx.GetStub().GetPrivateDataHash(studentPrivateCollection, key)
 	if err != nil {
-		return fmt.Errorf("failed to marshal approved record: %w", err)
+		return fmt.Errorf("failed to check private key: %w", err)
+	}
+	if hash == nil {
+		return nil
 	}
+	return ctx.GetStub().PurgePrivateData(studentPrivateCollection, key)
+}
 
-	err = ctx.GetStub().PutState(recordID, recordJSON)
+// purgeSensitiveDocuments purges a student's sensitive (AADHAAR, PHOTO) documents from the
+// private collection along with their document~student, document~hash and document~hashhistory
+// entries, and returns the purged document IDs
+func purgeSensitiveDocuments(ctx contractapi.TransactionContextInterface, rollNumber string) ([]string, error) {
+	iter, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(studentPrivateCollection, DocumentKey, []string{rollNumber})
 	if err != nil {
-		return fmt.Errorf("failed to put approved record state: %w", err)
+		return nil, fmt.Errorf("failed to query private documents of student %s: %w", rollNumber, err)
 	}
-
-	// Emit event
-	eventPayload := map[string]interface{}{
-		"recordID":   recordID,
-		"studentID":  record.StudentID,
-		"semester":   record.Semester,
-		"department": record.Department,
-		"sgpa":       record.SGPA,
-		"cgpa":       newCGPA,
-		"approvedBy": approverID,
-		"timestamp":  time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format("2006-01-02T15:04:05Z07:00"),
+	var studentKeys []string
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			iter.Close()
+			return nil, fmt.Errorf("failed to iterate private documents of student %s: %w", rollNumber, err)
+		}
+		studentKeys = append(studentKeys, kv.Key)
 	}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordApproved", eventJSON)
+	iter.Close()
 
-	return nil
-}
+	purged := []string{}
+	for _, studentKey := range studentKeys {
+		_, parts, err := ctx.GetStub().SplitCompositeKey(studentKey)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		docID := parts[1]
 
-// IssueCertificate issues a certificate with PDF hash (Enhanced with validation and RBAC)
-func (s *SmartContract) IssueCertificate(ctx contractapi.TransactionContextInterface,
-	certificateID, studentID, certType, pdfBase64, ipfsHash string) error {
+		keys := []string{studentKey, docID}
+		docKey, err := assetKey(ctx, DocTypeDocument, docID)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, docKey)
 
-	// Access Control: Only NITWarangalMSP can issue certificates
-	err := checkMSPAccess(ctx, NITWarangalMSP)
-	if err != nil {
-		return err
+		docJSON, err := getPrivateAssetState(ctx, studentPrivateCollection, DocTypeDocument, docID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private document %s: %w", docID, err)
+		}
+		if docJSON != nil {
+			var doc DocumentUpload
+			if err := json.Unmarshal(docJSON, &doc); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal document %s: %w", docID, err)
+			}
+			for _, index := range [][]string{
+				{DocumentHashKey, doc.SHA256Hash, docID},
+				{DocumentHashHistoryKey, doc.SHA256Hash, docID},
+			} {
+				indexKey, err := ctx.GetStub().CreateCompositeKey(index[0], index[1:])
+				if err != nil {
+					return nil, fmt.Errorf("failed to create %s key: %w", index[0], err)
+				}
+				keys = append(keys, indexKey)
+			}
+			// An entry from before index values became markers holds the ID of its document
+			legacyHashKey, err := ctx.GetStub().CreateCompositeKey(DocumentHashKey, []string{doc.SHA256Hash})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create hash key: %w", err)
+			}
+			owner, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, legacyHashKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read hash key: %w", err)
+			}
+			if string(owner) == docID {
+				keys = append(keys, legacyHashKey)
+			}
+		}
+
+		for _, key := range keys {
+			if err := purgePrivateKey(ctx, key); err != nil {
+				return nil, fmt.Errorf("failed to purge document %s: %w", docID, err)
+			}
+		}
+		purged = append(purged, docID)
 	}
+	return purged, nil
+}
 
-	// Validate certificate type
-	err = validateCertificateType(certType)
+// PurgeStudentPrivateDetails permanently removes a withdrawn or cancelled student's private
+// details, their Aadhaar index entry and their sensitive documents once the retention period
+// has passed. The data is purged from the private data store and its history; a tombstone
+// stays in public state.
+func (s *SmartContract) PurgeStudentPrivateDetails(ctx contractapi.TransactionContextInterface, rollNumber, reason string) error {
+	student, err := s.readStudent(ctx, rollNumber)
 	if err != nil {
 		return err
 	}
 
-	// Check if certificate already exists
-	existingCert, err := ctx.GetStub().GetState(certificateID)
-	if err != nil {
-		return fmt.Errorf("failed to check certificate existence: %v", err)
+	if student.Status != StatusWithdrawn && student.Status != StatusCancelled {
+		return fmt.Errorf("private details can only be purged for %s or %s students, current status: %s", StatusWithdrawn, StatusCancelled, student.Status)
 	}
-	if existingCert != nil {
-		return fmt.Errorf("certificate %s already exists", certificateID)
+	if len(reason) < 10 {
+		return fmt.Errorf("purge reason must be at least 10 characters")
 	}
 
-	// Verify student exists
-	exists, err := s.StudentExists(ctx, studentID)
+	tombstoneKey, err := ctx.GetStub().CreateCompositeKey(StudentPurgeKey, []string{rollNumber})
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to create composite key for purge tombstone: %w", err)
 	}
-	if !exists {
-		return fmt.Errorf("student %s does not exist", studentID)
-	}
-
-	// Calculate hash of PDF
-	hash := sha256.Sum256([]byte(pdfBase64))
-	pdfHash := hex.EncodeToString(hash[:])
-
-	clientID, err := ctx.GetClientIdentity().GetID()
+	existing, err := ctx.GetStub().GetState(tombstoneKey)
 	if err != nil {
-		return fmt.Errorf("failed to get client identity: %v", err)
+		return fmt.Errorf("failed to read purge tombstone: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("private details of student %s have already been purged", rollNumber)
 	}
 
-	// Get transaction timestamp
 	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
 	}
-	issueDate := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Set expiry date for BONAFIDE certificates (6 months)
-	var expiryDate time.Time
-	if certType == CertBonafide {
-		expiryDate = issueDate.AddDate(0, 6, 0) // 6 months validity
+	// Students whose status changed before StatusChangedAt was tracked fall back to ModifiedAt
+	statusChangedAt := student.StatusChangedAt
+	if statusChangedAt.IsZero() {
+		statusChangedAt = student.ModifiedAt
+	}
+	retainUntil := statusChangedAt.AddDate(PrivateDataRetentionYears, 0, 0)
+	if timestamp.Before(retainUntil) {
+		return fmt.Errorf("private details of student %s must be retained until %s", rollNumber, retainUntil.Format("2006-01-02"))
 	}
 
-	// Get student details to populate degree and CGPA
-	student, err := s.GetStudent(ctx, studentID)
+	privateDetailsJSON, err := ctx.GetStub().GetPrivateData(studentPrivateCollection, rollNumber)
 	if err != nil {
-		return fmt.Errorf("failed to get student details: %v", err)
+		return fmt.Errorf("failed to read private details for student %s: %w", rollNumber, err)
 	}
-
-	// Calculate degree name based on department
-	degreeAwarded := ""
-	if certType == CertDegree || certType == CertProvisional {
-		degreeAwarded = fmt.Sprintf("B.Tech in %s", student.Department)
+	if privateDetailsJSON == nil {
+		return fmt.Errorf("private details for student %s do not exist", rollNumber)
 	}
-
-	// Get final CGPA from student record
-	finalCGPA := student.CurrentCGPA
-
-	// Calculate isValid: not revoked and not expired
-	isValid := true // Initial state, will be computed dynamically in GetCertificate
-
-	certificate := Certificate{
-		CertificateID: certificateID,
-		StudentID:     studentID,
-		Type:          certType,
-		IssueDate:     issueDate,
-		ExpiryDate:    expiryDate,
-		PDFHash:       pdfHash,
-		IPFSHash:      ipfsHash,
-		IssuedBy:      clientID,
-		Verified:      true,
-		Revoked:       false,
-		DegreeAwarded: degreeAwarded,
-		FinalCGPA:     finalCGPA,
-		IsValid:       isValid,
+	var privateDetails StudentPrivateDetails
+	if err := json.Unmarshal(privateDetailsJSON, &privateDetails); err != nil {
+		return fmt.Errorf("failed to unmarshal private details: %w", err)
 	}
 
-	certJSON, err := json.Marshal(certificate)
+	fields := []string{"phone", "personalEmail"}
+	if privateDetails.AadhaarHash != "" {
+		aadhaarKey, err := ctx.GetStub().CreateCompositeKey(StudentAadhaarKey, []string{privateDetails.AadhaarHash})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key for aadhaar hash: %w", err)
+		}
+		if err := ctx.GetStub().PurgePrivateData(studentPrivateCollection, aadhaarKey); err != nil {
+			return fmt.Errorf("failed to purge aadhaar index: %w", err)
+		}
+		fields = append(fields, "aadhaarHash")
+	}
+	if err := ctx.GetStub().PurgePrivateData(studentPrivateCollection, rollNumber); err != nil {
+		return fmt.Errorf("failed to purge private details: %w", err)
+	}
+	documents, err := purgeSensitiveDocuments(ctx, rollNumber)
 	if err != nil {
 		return err
 	}
+	if len(documents) > 0 {
+		fields = append(fields, "documents")
+	}
 
-	err = ctx.GetStub().PutState(certificateID, certJSON)
+	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to get client ID: %w", err)
 	}
 
-	// Create composite key for student certificates
-	certKey, err := ctx.GetStub().CreateCompositeKey(CertStudentKey, []string{studentID, certificateID})
-	if err != nil {
-		return fmt.Errorf("failed to create composite key for certificate: %w", err)
+	tombstone := PurgeTombstone{
+		RollNumber: rollNumber,
+		Collection: studentPrivateCollection,
+		Fields:     fields,
+		Documents:  documents,
+		Reason:     reason,
+		PurgedBy:   clientID,
+		PurgedAt:   timestamp,
+		TxID:       ctx.GetStub().GetTxID(),
 	}
-	err = ctx.GetStub().PutState(certKey, []byte{0x00})
+	tombstoneJSON, err := json.Marshal(tombstone)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal purge tombstone: %w", err)
+	}
+	if err := ctx.GetStub().PutState(tombstoneKey, tombstoneJSON); err != nil {
+		return fmt.Errorf("failed to put purge tombstone: %w", err)
 	}
 
-	// Emit event
 	eventPayload := map[string]interface{}{
-		"certificateID": certificateID,
-		"studentID":     studentID,
-		"type":          certType,
-		"issuedBy":      clientID,
-		"issueDate":     issueDate.Format("2006-01-02T15:04:05Z07:00"),
-	}
-	if !expiryDate.IsZero() {
-		eventPayload["expiryDate"] = expiryDate.Format("2006-01-02T15:04:05Z07:00")
+		"rollNumber": rollNumber,
+		"fields":     fields,
+		"documents":  len(documents),
+		"purgedBy":   clientID,
+		"purgedAt":   timestamp,
 	}
 	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("CertificateIssued", eventJSON)
+	ctx.GetStub().SetEvent("StudentPrivateDetailsPurged", eventJSON)
 
 	return nil
 }
 
-// GetCertificate retrieves a certificate (Enhanced with revocation check)
-func (s *SmartContract) GetCertificate(ctx contractapi.TransactionContextInterface,
-	certificateID string) (*Certificate, error) {
-
-	certJSON, err := ctx.GetStub().GetState(certificateID)
+// GetStudentPurgeTombstone returns the tombstone left by PurgeStudentPrivateDetails
+func (s *SmartContract) GetStudentPurgeTombstone(ctx contractapi.TransactionContextInterface, rollNumber string) (*PurgeTombstone, error) {
+	tombstoneKey, err := ctx.GetStub().CreateCompositeKey(StudentPurgeKey, []string{rollNumber})
 	if err != nil {
-		return nil, fmt.Errorf("failed to read certificate: %v", err)
-	}
-	if certJSON == nil {
-		return nil, fmt.Errorf("certificate %s does not exist", certificateID)
+		return nil, fmt.Errorf("failed to create composite key for purge tombstone: %w", err)
 	}
-
-	var certificate Certificate
-	err = json.Unmarshal(certJSON, &certificate)
+	tombstoneJSON, err := ctx.GetStub().GetState(tombstoneKey)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read purge tombstone: %w", err)
+	}
+	if tombstoneJSON == nil {
+		return nil, fmt.Errorf("private details of student %s have not been purged", rollNumber)
 	}
 
-	// Get current time for expiry check
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	currentTime := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-
-	// Dynamically compute IsValid: not revoked AND (no expiry OR not expired)
-	certificate.IsValid = !certificate.Revoked &&
-		(certificate.ExpiryDate.IsZero() || currentTime.Before(certificate.ExpiryDate))
-
-	// Check if certificate is expired (for BONAFIDE certificates)
-	if certificate.Type == CertBonafide && !certificate.ExpiryDate.IsZero() {
-		if currentTime.After(certificate.ExpiryDate) {
-			certificate.Verified = false // Mark as not verified if expired
-		}
+	var tombstone PurgeTombstone
+	if err := json.Unmarshal(tombstoneJSON, &tombstone); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal purge tombstone: %w", err)
 	}
+	return &tombstone, nil
+}
 
-	return &certificate, nil
+// BranchChange is one department transfer of a student
+type BranchChange struct {
+	FromDepartment    string    `json:"fromDepartment"`
+	ToDepartment      string    `json:"toDepartment"`
+	EffectiveSemester int       `json:"effectiveSemester"`
+	ApprovalDocID     string    `json:"approvalDocId"`
+	Reason            string    `json:"reason"`
+	RehomedRecords    []string  `json:"rehomedRecords"`
+	ChangedBy         string    `json:"changedBy"`
+	ChangedAt         time.Time `json:"changedAt"`
+	TxID              string    `json:"txId"`
 }
 
-// VerifyCertificate verifies a certificate by comparing PDF hash (Enhanced with revocation and expiry check)
-func (s *SmartContract) VerifyCertificate(ctx contractapi.TransactionContextInterface,
-	certificateID, pdfBase64 string) (bool, error) {
+// BranchChange transfers a student to another department from effectiveSemester onwards.
+// Records for earlier semesters stay with the department that taught them; unreleased records
+// for the effective semester and later move to the new department, including their grades
+// collection. The transfer is rejected if a released record already exists for those semesters.
+func (s *SmartContract) BranchChange(ctx contractapi.TransactionContextInterface,
+	rollNumber, newDepartment string, effectiveSemester int, approvalDocID, reason string) error {
+
+	newDepartment = strings.ToUpper(newDepartment)
 
-	certJSON, err := ctx.GetStub().GetState(certificateID)
+	student, err := s.readStudent(ctx, rollNumber)
 	if err != nil {
-		return false, fmt.Errorf("failed to read certificate: %v", err)
+		return err
 	}
-	if certJSON == nil {
-		return false, fmt.Errorf("certificate %s does not exist", certificateID)
+	oldDepartment := student.Department
+	if oldDepartment == newDepartment {
+		return fmt.Errorf("student is already in department %s", newDepartment)
+	}
+	if err := s.checkStudentStatusFor(ctx, "BranchChange", student, effectiveSemester); err != nil {
+		return err
 	}
 
-	var certificate Certificate
-	err = json.Unmarshal(certJSON, &certificate)
+	exists, err := s.departmentExists(ctx, newDepartment)
 	if err != nil {
-		return false, err
+		return err
 	}
-
-	// Check if certificate is revoked
-	if certificate.Revoked {
-		return false, fmt.Errorf("certificate has been revoked: %s", certificate.RevocationReason)
+	if !exists {
+		return fmt.Errorf("department %s does not exist", newDepartment)
+	}
+	if err := validateSemester(effectiveSemester); err != nil {
+		return err
+	}
+	if reason == "" {
+		return fmt.Errorf("reason required for branch change")
+	}
+	if approvalDocID == "" {
+		return fmt.Errorf("approval document required for branch change")
+	}
+	if err := s.validateSupportingDocument(ctx, rollNumber, approvalDocID); err != nil {
+		return err
 	}
 
-	// Check if certificate is expired (for BONAFIDE certificates)
-	if certificate.Type == CertBonafide && !certificate.ExpiryDate.IsZero() {
-		txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-		currentTime := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-		if currentTime.After(certificate.ExpiryDate) {
-			return false, fmt.Errorf("certificate has expired on %s", certificate.ExpiryDate.Format("2006-01-02"))
+	// Collect the records to re-home before changing anything
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(StudentRecordKey, []string{rollNumber})
+	if err != nil {
+		return fmt.Errorf("failed to query student records: %w", err)
+	}
+	var rehome []*AcademicRecord
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			iter.Close()
+			return err
 		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		record, err := s.readAcademicRecord(ctx, parts[1])
+		if err != nil {
+			iter.Close()
+			return err
+		}
+		if record.Semester < effectiveSemester {
+			continue
+		}
+		if isRecordReleased(record.Status) {
+			iter.Close()
+			return fmt.Errorf("record %s for semester %d is already released, the branch change must take effect after it", record.RecordID, record.Semester)
+		}
+		rehome = append(rehome, record)
 	}
+	iter.Close()
 
-	// Calculate hash of provided PDF
-	hash := sha256.Sum256([]byte(pdfBase64))
-	providedHash := hex.EncodeToString(hash[:])
+	// Get transaction timestamp and client ID
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Verify hash matches
-	if providedHash != certificate.PDFHash {
-		return false, nil
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
 	}
 
-	return true, nil
-}
+	// Re-home future records and their indexes and approval records
+	rehomedIDs := []string{}
+	for _, record := range rehome {
+		oldKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptKey, []string{oldDepartment, rollNumber, record.RecordID})
+		if err != nil {
+			return fmt.Errorf("failed to create old department record key: %w", err)
+		}
+		if err := ctx.GetStub().DelState(oldKey); err != nil {
+			return fmt.Errorf("failed to delete old department record key: %w", err)
+		}
+		newKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptKey, []string{newDepartment, rollNumber, record.RecordID})
+		if err != nil {
+			return fmt.Errorf("failed to create new department record key: %w", err)
+		}
+		if err := ctx.GetStub().PutState(newKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("failed to put new department record key: %w", err)
+		}
+		if record.AcademicYear != "" {
+			oldYearKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptYearKey, []string{oldDepartment, record.AcademicYear, rollNumber, record.RecordID})
+			if err != nil {
+				return fmt.Errorf("failed to create old department year record key: %w", err)
+			}
+			if err := ctx.GetStub().DelState(oldYearKey); err != nil {
+				return fmt.Errorf("failed to delete old department year record key: %w", err)
+			}
+			newYearKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptYearKey, []string{newDepartment, record.AcademicYear, rollNumber, record.RecordID})
+			if err != nil {
+				return fmt.Errorf("failed to create new department year record key: %w", err)
+			}
+			if err := ctx.GetStub().PutState(newYearKey, []byte{0x00}); err != nil {
+				return fmt.Errorf("failed to put new department year record key: %w", err)
+			}
+		}
 
-// RevokeCertificate revokes a certificate (NEW - with multi-party approval)
-func (s *SmartContract) RevokeCertificate(ctx contractapi.TransactionContextInterface,
-	certificateID, reason string) error {
+		record.Department = newDepartment
+		if err := s.putAcademicRecord(ctx, record); err != nil {
+			return err
+		}
 
-	// Access Control: Only NITWarangalMSP can revoke certificates
-	err := checkMSPAccess(ctx, NITWarangalMSP)
-	if err != nil {
-		return err
+		approvalKey, err := ctx.GetStub().CreateCompositeKey(ApprovalKey, []string{record.RecordID})
+		if err != nil {
+			return fmt.Errorf("failed to create approval key: %w", err)
+		}
+		approvalJSON, err := ctx.GetStub().GetState(approvalKey)
+		if err != nil {
+			return fmt.Errorf("failed to read approval record: %w", err)
+		}
+		if approvalJSON != nil {
+			var ar ApprovalRecord
+			if err := json.Unmarshal(approvalJSON, &ar); err != nil {
+				return fmt.Errorf("failed to unmarshal approval record: %w", err)
+			}
+			ar.Department = newDepartment
+			if err := s.saveApprovalRecord(ctx, &ar); err != nil {
+				return err
+			}
+		}
+		rehomedIDs = append(rehomedIDs, record.RecordID)
 	}
 
-	// Get certificate
-	certJSON, err := ctx.GetStub().GetState(certificateID)
+	// Update student record
+	student.Department = newDepartment
+	student.ModifiedBy = clientID
+	student.ModifiedAt = timestamp
+
+	updatedStudentJSON, err := json.Marshal(student)
 	if err != nil {
-		return fmt.Errorf("failed to read certificate: %v", err)
-	}
-	if certJSON == nil {
-		return fmt.Errorf("certificate %s does not exist", certificateID)
+		return fmt.Errorf("failed to marshal student: %w", err)
 	}
 
-	var certificate Certificate
-	err = json.Unmarshal(certJSON, &certificate)
+	// Update main record
+	err = putAssetState(ctx, DocTypeStudent, rollNumber, updatedStudentJSON)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to update student record: %w", err)
 	}
 
-	// Check if already revoked
-	if certificate.Revoked {
-		return fmt.Errorf("certificate %s is already revoked", certificateID)
+	// Move the department key
+	oldDeptKey, err := ctx.GetStub().CreateCompositeKey(StudentDeptKey, []string{oldDepartment, rollNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create old department key: %w", err)
 	}
-
-	// Validate reason
-	if len(reason) < 10 {
-		return fmt.Errorf("revocation reason must be at least 10 characters")
+	if err := ctx.GetStub().DelState(oldDeptKey); err != nil {
+		return fmt.Errorf("failed to delete old department key: %w", err)
 	}
-
-	// Get revoker identity
-	clientID, err := ctx.GetClientIdentity().GetID()
+	newDeptKey, err := ctx.GetStub().CreateCompositeKey(StudentDeptKey, []string{newDepartment, rollNumber})
 	if err != nil {
-		return fmt.Errorf("failed to get client identity: %v", err)
+		return fmt.Errorf("failed to create new department key: %w", err)
 	}
-
-	// Get timestamp
-	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	err = ctx.GetStub().PutState(newDeptKey, []byte{0x00})
 	if err != nil {
-		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+		return fmt.Errorf("failed to put new department key: %w", err)
 	}
-	revokedAt := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-
-	// Update certificate
-	certificate.Revoked = true
-	certificate.RevokedBy = clientID
-	certificate.RevokedAt = revokedAt
-	certificate.RevocationReason = reason
-	certificate.Verified = false
-	certificate.IsValid = false // Mark as invalid when revoked
 
-	updatedCertJSON, err := json.Marshal(certificate)
+	// Append to branch change history
+	history, err := s.readBranchChangeHistory(ctx, rollNumber)
 	if err != nil {
 		return err
 	}
-
-	err = ctx.GetStub().PutState(certificateID, updatedCertJSON)
+	history = append(history, BranchChange{
+		FromDepartment:    oldDepartment,
+		ToDepartment:      newDepartment,
+		EffectiveSemester: effectiveSemester,
+		ApprovalDocID:     approvalDocID,
+		Reason:            reason,
+		RehomedRecords:    rehomedIDs,
+		ChangedBy:         clientID,
+		ChangedAt:         timestamp,
+		TxID:              ctx.GetStub().GetTxID(),
+	})
+	historyJSON, err := json.Marshal(history)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal branch change history: %w", err)
+	}
+	historyKey, err := ctx.GetStub().CreateCompositeKey(StudentBranchHistoryKey, []string{rollNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key for branch change history: %w", err)
+	}
+	if err := ctx.GetStub().PutState(historyKey, historyJSON); err != nil {
+		return fmt.Errorf("failed to put branch change history: %w", err)
 	}
 
-	// Emit event
+	// Emit department change event
 	eventPayload := map[string]interface{}{
-		"certificateID": certificateID,
-		"studentID":     certificate.StudentID,
-		"type":          certificate.Type,
-		"revokedBy":     clientID,
-		"revokedAt":     revokedAt.Format("2006-01-02T15:04:05Z07:00"),
-		"reason":        reason,
+		"rollNumber":        rollNumber,
+		"oldDepartment":     oldDepartment,
+		"newDepartment":     newDepartment,
+		"effectiveSemester": effectiveSemester,
+		"approvalDocId":     approvalDocID,
+		"rehomedRecords":    rehomedIDs,
+		"modifiedBy":        clientID,
+		"modifiedAt":        timestamp,
 	}
 	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("CertificateRevoked", eventJSON)
+	err = ctx.GetStub().SetEvent("StudentDepartmentChanged", eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
+	}
 
 	return nil
 }
 
-// GetCertificatesByStudent retrieves all certificates for a student (NEW)
-func (s *SmartContract) GetCertificatesByStudent(ctx contractapi.TransactionContextInterface,
-	studentID string) ([]*Certificate, error) {
-
-	// Use composite key to query certificates by studentID
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(CertStudentKey, []string{studentID})
+// readBranchChangeHistory loads a student's branch change history without access control
+func (s *SmartContract) readBranchChangeHistory(ctx contractapi.TransactionContextInterface, rollNumber string) ([]BranchChange, error) {
+	historyKey, err := ctx.GetStub().CreateCompositeKey(StudentBranchHistoryKey, []string{rollNumber})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get certificates by student: %w", err)
+		return nil, fmt.Errorf("failed to create composite key for branch change history: %w", err)
+	}
+	historyJSON, err := ctx.GetStub().GetState(historyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read branch change history: %w", err)
 	}
-	defer resultsIterator.Close()
 
-	var certificates []*Certificate
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
+	history := []BranchChange{}
+	if historyJSON != nil {
+		if err := json.Unmarshal(historyJSON, &history); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal branch change history: %w", err)
 		}
+	}
+	return history, nil
+}
 
-		// Split the composite key to extract certificateID
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, err
-		}
+// GetBranchChangeHistory returns every department transfer of a student, oldest first
+func (s *SmartContract) GetBranchChangeHistory(ctx contractapi.TransactionContextInterface, rollNumber string) ([]BranchChange, error) {
+	if err := s.authorizeStudentReadByID(ctx, rollNumber); err != nil {
+		return nil, err
+	}
+	return s.readBranchChangeHistory(ctx, rollNumber)
+}
 
-		if len(compositeKeyParts) < 2 {
-			continue
-		}
-		certificateID := compositeKeyParts[1]
+// FieldChange is the old and new value of one corrected field
+type FieldChange struct {
+	Field    string `json:"field"`
+	OldValue string `json:"oldValue"`
+	NewValue string `json:"newValue"`
+}
 
-		// Fetch the actual certificate
-		certJSON, err := ctx.GetStub().GetState(certificateID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read certificate %s: %v", certificateID, err)
-		}
-		if certJSON == nil {
-			continue
-		}
+// ProfileUpdate is one correction of a student's public profile
+type ProfileUpdate struct {
+	Changes   []FieldChange `json:"changes"`
+	Reason    string        `json:"reason"`
+	ChangedBy string        `json:"changedBy"`
+	ChangedAt time.Time     `json:"changedAt"`
+	TxID      string        `json:"txId"`
+}
 
-		var certificate Certificate
-		err = json.Unmarshal(certJSON, &certificate)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal certificate %s: %v", certificateID, err)
-		}
+// correctableProfileFields are the public student fields UpdateStudentProfile may change.
+// Status and department have their own transactions; contact details are private.
+var correctableProfileFields = map[string]bool{
+	"name":              true,
+	"enrollmentYear":    true,
+	"admissionCategory": true,
+}
 
-		// Get current time for expiry check
-		txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-		currentTime := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+// UpdateStudentProfile corrects public profile fields entered wrongly at admission.
+// updatesJSON is an object of field name to new value, e.g. {"name":"...","enrollmentYear":2023};
+// every field that actually changes is recorded in the student's profile history.
+func (s *SmartContract) UpdateStudentProfile(ctx contractapi.TransactionContextInterface,
+	rollNumber, updatesJSON, reason string) error {
 
-		// Dynamically compute IsValid: not revoked AND (no expiry OR not expired)
-		certificate.IsValid = !certificate.Revoked &&
-			(certificate.ExpiryDate.IsZero() || currentTime.Before(certificate.ExpiryDate))
+	reason = strings.TrimSpace(reason)
+	if len(reason) < 10 {
+		return fmt.Errorf("a reason of at least 10 characters is required to correct a student profile")
+	}
 
-		certificates = append(certificates, &certificate)
+	var updates map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(updatesJSON), &updates); err != nil {
+		return fmt.Errorf("invalid updates JSON: %w", err)
+	}
+	if len(updates) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+	for field := range updates {
+		if !correctableProfileFields[field] {
+			return fmt.Errorf("field %s cannot be changed with UpdateStudentProfile", field)
+		}
 	}
 
-	return certificates, nil
-}
+	student, err := s.readStudent(ctx, rollNumber)
+	if err != nil {
+		return err
+	}
+	oldYear := student.EnrollmentYear
 
-// GetStudentHistory retrieves all academic records for a student (Fixed to read actual records)
-func (s *SmartContract) GetStudentHistory(ctx contractapi.TransactionContextInterface, studentID string) ([]*AcademicRecord, error) {
-	// Use composite key to query records by studentID
-	// Format: student~record~{studentID}~{recordID}
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(StudentRecordKey, []string{studentID})
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get student history: %w", err)
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
 	}
-	defer resultsIterator.Close()
+	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	var records []*AcademicRecord
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, err
+	var changes []FieldChange
+	if raw, ok := updates["name"]; ok {
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return fmt.Errorf("name must be a string")
 		}
-
-		// Split the composite key to extract recordID
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, err
+		name = strings.TrimSpace(name)
+		if len(name) < 3 || len(name) > 100 {
+			return fmt.Errorf("name must be between 3 and 100 characters")
 		}
-
-		// compositeKeyParts[0] is studentID, compositeKeyParts[1] is recordID
-		if len(compositeKeyParts) < 2 {
-			continue
+		if name != student.Name {
+			changes = append(changes, FieldChange{Field: "name", OldValue: student.Name, NewValue: name})
+			student.Name = name
 		}
-		recordID := compositeKeyParts[1]
-
-		// Fetch the actual record using recordID
-		recordJSON, err := ctx.GetStub().GetState(recordID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read record %s: %v", recordID, err)
+	}
+	if raw, ok := updates["enrollmentYear"]; ok {
+		var year int
+		if err := json.Unmarshal(raw, &year); err != nil {
+			return fmt.Errorf("enrollmentYear must be a number")
 		}
-		if recordJSON == nil {
-			continue
+		if year < 1950 || year > timestamp.Year()+1 {
+			return fmt.Errorf("invalid enrollment year %d", year)
 		}
-
-		var record AcademicRecord
-		err = json.Unmarshal(recordJSON, &record)
+		if year != student.EnrollmentYear {
+			changes = append(changes, FieldChange{Field: "enrollmentYear", OldValue: fmt.Sprintf("%d", student.EnrollmentYear), NewValue: fmt.Sprintf("%d", year)})
+			student.EnrollmentYear = year
+		}
+	}
+	if raw, ok := updates["admissionCategory"]; ok {
+		var category string
+		if err := json.Unmarshal(raw, &category); err != nil {
+			return fmt.Errorf("admissionCategory must be a string")
+		}
+		category, err = resolveAdmissionCategory(ctx, category)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal record %s: %v", recordID, err)
+			return err
+		}
+		if category != student.AdmissionCategory {
+			changes = append(changes, FieldChange{Field: "admissionCategory", OldValue: student.AdmissionCategory, NewValue: category})
+			student.AdmissionCategory = category
 		}
-		records = append(records, &record)
+	}
+	if len(changes) == 0 {
+		return fmt.Errorf("the updates do not change student %s", rollNumber)
 	}
 
-	return records, nil
-}
-
-// GetStudentCGPA retrieves the current CGPA for a student
-func (s *SmartContract) GetStudentCGPA(ctx contractapi.TransactionContextInterface, studentID string) (float64, error) {
-	student, err := s.GetStudent(ctx, studentID)
+	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return 0, fmt.Errorf("failed to get student: %w", err)
+		return fmt.Errorf("failed to get client identity: %w", err)
 	}
-	return student.CurrentCGPA, nil
-}
 
-// GetAllStudents retrieves all students using a composite key for efficiency
-func (s *SmartContract) GetAllStudents(ctx contractapi.TransactionContextInterface) ([]*Student, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(StudentAllKey, []string{})
+	student.ModifiedBy = clientID
+	student.ModifiedAt = timestamp
+	studentJSON, err := json.Marshal(student)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all students: %w", err)
+		return fmt.Errorf("failed to marshal student: %w", err)
+	}
+	if err := putAssetState(ctx, DocTypeStudent, rollNumber, studentJSON); err != nil {
+		return fmt.Errorf("failed to update student record: %w", err)
 	}
-	defer resultsIterator.Close()
 
-	var students []*Student
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
+	// Move the year key if the enrollment year was corrected
+	if student.EnrollmentYear != oldYear {
+		oldYearKey, err := ctx.GetStub().CreateCompositeKey(StudentYearKey, []string{fmt.Sprintf("%d", oldYear), rollNumber})
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate over students: %w", err)
+			return fmt.Errorf("failed to create old year key: %w", err)
 		}
-
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err := ctx.GetStub().DelState(oldYearKey); err != nil {
+			return fmt.Errorf("failed to delete old year key: %w", err)
+		}
+		newYearKey, err := ctx.GetStub().CreateCompositeKey(StudentYearKey, []string{fmt.Sprintf("%d", student.EnrollmentYear), rollNumber})
 		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %w", err)
+			return fmt.Errorf("failed to create new year key: %w", err)
 		}
-		if len(compositeKeyParts) < 1 {
-			return nil, fmt.Errorf("invalid composite key for student")
-		}
-		rollNumber := compositeKeyParts[0]
-
-		student, err := s.GetStudent(ctx, rollNumber)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get student %s: %w", rollNumber, err)
+		if err := ctx.GetStub().PutState(newYearKey, []byte{0x00}); err != nil {
+			return fmt.Errorf("failed to put new year key: %w", err)
 		}
-		students = append(students, student)
 	}
 
-	return students, nil
-}
-
-// DEPRECATED: Use GetStudentsByDepartment instead
-// GetStudentsByFaculty retrieves all students in the same department as the faculty
-// For faculty to view students in their department
-// This function is kept for backward compatibility but should not be used
-func (s *SmartContract) GetStudentsByFaculty(ctx contractapi.TransactionContextInterface, facultyID string, facultyDepartment string) ([]*Student, error) {
-	// Redirect to GetStudentsByDepartment
-	return s.GetStudentsByDepartment(ctx, facultyDepartment)
-}
-
-// Helper function to calculate grades (Enhanced with custom NIT Warangal grade system)
-func calculateGrades(courses []Course) (float64, float64) {
-	totalPoints := 0.0
-	totalCredits := 0.0
-
-	// Custom NIT Warangal grade point mapping (10-point scale)
-	gradePoints := map[string]float64{
-		GradeS: 10.0, // Outstanding
-		GradeA: 9.0,  // Excellent
-		GradeB: 8.0,  // Very Good
-		GradeC: 7.0,  // Good
-		GradeD: 6.0,  // Average
-		GradeP: 5.0,  // Pass
-		GradeU: 0.0,  // Fail
-		GradeR: 0.0,  // Reappear
+	// Append to profile history
+	history, err := s.readProfileHistory(ctx, rollNumber)
+	if err != nil {
+		return err
 	}
-
-	for _, course := range courses {
-		totalCredits += course.Credits
-		if gp, ok := gradePoints[course.Grade]; ok {
-			totalPoints += gp * course.Credits
-		}
+	history = append(history, ProfileUpdate{
+		Changes:   changes,
+		Reason:    reason,
+		ChangedBy: clientID,
+		ChangedAt: timestamp,
+		TxID:      ctx.GetStub().GetTxID(),
+	})
+	historyJSON, err := json.Marshal(history)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile history: %w", err)
+	}
+	historyKey, err := ctx.GetStub().CreateCompositeKey(StudentProfileHistoryKey, []string{rollNumber})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key for profile history: %w", err)
+	}
+	if err := ctx.GetStub().PutState(historyKey, historyJSON); err != nil {
+		return fmt.Errorf("failed to put profile history: %w", err)
 	}
 
-	sgpa := 0.0
-	if totalCredits > 0 {
-		sgpa = totalPoints / totalCredits
+	// Emit profile update event
+	eventPayload := map[string]interface{}{
+		"rollNumber": rollNumber,
+		"changes":    changes,
+		"reason":     reason,
+		"modifiedBy": clientID,
+		"modifiedAt": timestamp,
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	err = ctx.GetStub().SetEvent("StudentProfileUpdated", eventJSON)
+	if err != nil {
+		return fmt.Errorf("failed to set event: %v", err)
 	}
 
-	return totalCredits, sgpa
+	return nil
 }
 
-// Calculate CGPA based on all approved records (Enhanced)
-func (s *SmartContract) calculateCGPA(ctx contractapi.TransactionContextInterface, studentID string, currentSemester int) (float64, float64, error) {
-	// Get all approved academic records for the student
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(RecordStatusKey, []string{RecordApproved, studentID})
+// readProfileHistory loads a student's profile corrections without access control
+func (s *SmartContract) readProfileHistory(ctx contractapi.TransactionContextInterface, rollNumber string) ([]ProfileUpdate, error) {
+	historyKey, err := ctx.GetStub().CreateCompositeKey(StudentProfileHistoryKey, []string{rollNumber})
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to query approved records: %w", err)
+		return nil, fmt.Errorf("failed to create composite key for profile history: %w", err)
+	}
+	historyJSON, err := ctx.GetStub().GetState(historyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile history: %w", err)
 	}
-	defer resultsIterator.Close()
-
-	totalPoints := 0.0
-	totalCredits := 0.0
-
-	for resultsIterator.HasNext() {
-		response, err := resultsIterator.Next()
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to iterate approved records: %w", err)
-		}
-
-		_, keyParts, err := ctx.GetStub().SplitCompositeKey(response.Key)
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to split composite key for record: %w", err)
-		}
-		recordID := keyParts[len(keyParts)-1]
-
-		record, err := s.GetAcademicRecord(ctx, recordID)
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to get academic record %s: %w", recordID, err)
-		}
 
-		// Ensure we only include semesters up to the current one
-		if record.Semester <= currentSemester {
-			totalPoints += record.SGPA * record.TotalCredits
-			totalCredits += record.TotalCredits
+	history := []ProfileUpdate{}
+	if historyJSON != nil {
+		if err := json.Unmarshal(historyJSON, &history); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal profile history: %w", err)
 		}
 	}
+	return history, nil
+}
 
-	if totalCredits == 0 {
-		return 0, 0, nil // Avoid division by zero
+// GetStudentProfileHistory returns every correction of a student's profile, oldest first
+func (s *SmartContract) GetStudentProfileHistory(ctx contractapi.TransactionContextInterface, rollNumber string) ([]ProfileUpdate, error) {
+	if err := s.authorizeStudentReadByID(ctx, rollNumber); err != nil {
+		return nil, err
 	}
+	return s.readProfileHistory(ctx, rollNumber)
+}
 
-	cgpa := totalPoints / totalCredits
-	return cgpa, totalCredits, nil
+// StudentExists checks if a student exists
+func (s *SmartContract) StudentExists(ctx contractapi.TransactionContextInterface, studentID string) (bool, error) {
+	studentJSON, err := getAssetState(ctx, DocTypeStudent, studentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %w", err)
+	}
+	return studentJSON != nil, nil
 }
 
-// calculateCGPAIncludingCurrent calculates CGPA including the current record being approved
-func (s *SmartContract) calculateCGPAIncludingCurrent(ctx contractapi.TransactionContextInterface, studentID string, currentSemester int, currentSGPA float64, currentCredits float64) (float64, float64, error) {
-	// Get all approved academic records for the student (excluding current semester)
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(RecordStatusKey, []string{RecordApproved, studentID})
+// recordExists checks if an academic record exists.
+func (s *SmartContract) recordExists(ctx contractapi.TransactionContextInterface, recordID string) (bool, error) {
+	recordJSON, err := getAssetState(ctx, DocTypeRecord, recordID)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to query approved records: %w", err)
+		return false, fmt.Errorf("failed to read from world state: %w", err)
 	}
-	defer resultsIterator.Close()
+	return recordJSON != nil, nil
+}
 
-	totalPoints := 0.0
-	totalCredits := 0.0
+// CreateAcademicRecord creates a new academic record (Enhanced with validation and access control)
+func (s *SmartContract) CreateAcademicRecord(ctx contractapi.TransactionContextInterface,
+	recordID, rollNumber string, semester int, year string, department string, coursesJSON string) error {
 
-	for resultsIterator.HasNext() {
-		response, err := resultsIterator.Next()
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to iterate approved records: %w", err)
-		}
+	// Department users can only create records for their own department
+	if err := checkDepartmentAccess(ctx, department); err != nil {
+		return fmt.Errorf("department user cannot create a record for another department: %w", err)
+	}
 
-		_, keyParts, err := ctx.GetStub().SplitCompositeKey(response.Key)
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to split composite key for record: %w", err)
-		}
-		recordID := keyParts[len(keyParts)-1]
+	// Check if record already exists
+	exists, err := s.recordExists(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("academic record %s already exists", recordID)
+	}
 
-		record, err := s.GetAcademicRecord(ctx, recordID)
-		if err != nil {
-			return 0, 0, fmt.Errorf("failed to get academic record %s: %w", recordID, err)
-		}
+	// Verify student exists
+	student, err := s.readStudent(ctx, rollNumber)
+	if err != nil {
+		return err
+	}
 
-		// Only include semesters before the current one (avoid double-counting)
-		if record.Semester < currentSemester {
-			totalPoints += record.SGPA * record.TotalCredits
-			totalCredits += record.TotalCredits
-		}
+	// Validate semester (1-8)
+	if err := validateSemester(semester); err != nil {
+		return err
 	}
 
-	// Add current semester's data
-	totalPoints += currentSGPA * currentCredits
-	totalCredits += currentCredits
+	year = strings.TrimSpace(year)
+	if err := validateAcademicYear(year); err != nil {
+		return err
+	}
 
-	if totalCredits == 0 {
-		return 0, 0, nil // Avoid division by zero
+	if err := s.checkStudentStatusFor(ctx, "CreateAcademicRecord", student, semester); err != nil {
+		return err
 	}
 
-	cgpa := totalPoints / totalCredits
-	return cgpa, totalCredits, nil
-}
+	// One record per semester: an earlier record must be superseded before it is replaced
+	replaced, err := s.replaceableSemesterRecord(ctx, rollNumber, semester)
+	if err != nil {
+		return err
+	}
+	supersedes := ""
+	if replaced != nil {
+		supersedes = replaced.RecordID
+	}
 
-// ============================================================================
-// Phase 2: Query Functions with Pagination
-// ============================================================================
+	// Grades must not appear in the proposal arguments, which are recorded in the block
+	if coursesJSON != "" {
+		return fmt.Errorf("courses must be passed as transient data, not as an argument")
+	}
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to get transient map: %w", err)
+	}
+	coursesBytes, ok := transientMap["courses"]
+	if !ok || len(coursesBytes) == 0 {
+		return fmt.Errorf("courses must be provided in transient data")
+	}
 
-// PaginatedQueryResult represents paginated query results
-type PaginatedQueryResult struct {
-	Records     interface{} `json:"records"`
-	Bookmark    string      `json:"bookmark"`
-	RecordCount int         `json:"recordCount"`
-	HasMore     bool        `json:"hasMore"`
-}
+	var courses []Course
+	err = json.Unmarshal(coursesBytes, &courses)
+	if err != nil {
+		return fmt.Errorf("failed to parse courses: %v", err)
+	}
 
-// QueryStudentsByDepartment returns students in a department with pagination
-func (s *SmartContract) QueryStudentsByDepartment(ctx contractapi.TransactionContextInterface, department string, bookmark string, pageSize int) (*PaginatedQueryResult, error) {
-	// Validate page size
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50 // Default to 50 records per page
+	if len(courses) == 0 {
+		return fmt.Errorf("at least one course is required")
 	}
 
-	// Check department access
-	err := checkDepartmentAccess(ctx, department)
+	salt, err := gradesSalt(ctx)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	// Query using composite key: student~dept~{Department}~{RollNumber}
-	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(StudentDeptKey, []string{department}, int32(pageSize), bookmark)
+	// Grades are validated and computed with the scheme in force for the program and year
+	scheme, err := resolveGradingScheme(ctx, department, year)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query students by department: %w", err)
+		return err
 	}
-	defer resultsIterator.Close()
 
-	var students []*Student
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+	// Validate each course and calculate total credits
+	totalCredits := 0.0
+	for i, course := range courses {
+		// Validate course code
+		if len(course.CourseCode) < 3 || len(course.CourseCode) > 20 {
+			return fmt.Errorf("course %d: invalid course code length (must be 3-20 characters)", i+1)
 		}
 
-		// Extract student roll number from composite key
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		// Validate course name
+		if len(course.CourseName) < 3 || len(course.CourseName) > 100 {
+			return fmt.Errorf("course %d: invalid course name length (must be 3-100 characters)", i+1)
 		}
-		rollNumber := compositeKeyParts[1] // student~dept~{Department}~{RollNumber}
 
-		// Get actual student record
-		studentBytes, err := ctx.GetStub().GetState(rollNumber)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read student %s: %v", rollNumber, err)
-		}
-		if studentBytes == nil {
-			continue // Skip if student doesn't exist
+		// Validate credits (0.5-6)
+		if err := validateCredits(course.Credits); err != nil {
+			return fmt.Errorf("course %d (%s): %v", i+1, course.CourseCode, err)
 		}
 
-		var student Student
-		err = json.Unmarshal(studentBytes, &student)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
+		// Validate grade against the grading scheme
+		if err := validateGrade(scheme, course.Grade); err != nil {
+			return fmt.Errorf("course %d (%s): %v", i+1, course.CourseCode, err)
 		}
 
-		students = append(students, &student)
+		totalCredits += course.Credits
 	}
 
-	result := &PaginatedQueryResult{
-		Records:     students,
-		Bookmark:    metadata.Bookmark,
-		RecordCount: int(metadata.FetchedRecordsCount),
-		HasMore:     metadata.Bookmark != "",
+	// Validate total credits per semester (16-30)
+	if totalCredits < 16.0 || totalCredits > 30.0 {
+		return fmt.Errorf("total semester credits %.1f out of range (must be 16-30)", totalCredits)
 	}
 
-	return result, nil
-}
-
-// QueryStudentsByYear returns students by enrollment year with pagination
-func (s *SmartContract) QueryStudentsByYear(ctx contractapi.TransactionContextInterface, year int, bookmark string, pageSize int) (*PaginatedQueryResult, error) {
-	// Validate page size
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50
-	}
+	// Calculate GPA for this semester
+	gpaCredits, earnedCredits, sgpa := calculateGrades(courses, scheme)
 
-	// Validate year
-	if year < 1950 {
-		return nil, fmt.Errorf("invalid enrollment year: %d", year)
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %v", err)
 	}
 
-	// Query using composite key: student~year~{EnrollmentYear}~{RollNumber}
-	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(StudentYearKey, []string{fmt.Sprintf("%d", year)}, int32(pageSize), bookmark)
+	// Get transaction timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return nil, fmt.Errorf("failed to query students by year: %w", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
-	defer resultsIterator.Close()
+	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	var students []*Student
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate query results: %v", err)
-		}
+	// Create academic record with DRAFT status initially
+	record := AcademicRecord{
+		DocType:       DocTypeRecord,
+		RecordID:      recordID,
+		StudentID:     rollNumber, // Using rollNumber as student identifier
+		Department:    department,
+		Semester:      semester,
+		AcademicYear:  year,
+		Courses:       courses,
+		TotalCredits:  totalCredits,
+		SGPA:          sgpa,
+		GPACredits:    gpaCredits,
+		EarnedCredits: earnedCredits,
+		CGPA:          0.0, // Will be calculated on approval
+		Timestamp:     timestamp,
+		SubmittedBy:   clientID,
+		Status:        StatusDraft,
+		ApprovedBy:    "",
+		RejectionNote: "", // Initialize to empty string
+		Supersedes:    supersedes,
 
-		// Extract student roll number from composite key
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %v", err)
-		}
-		rollNumber := compositeKeyParts[1] // student~year~{Year}~{RollNumber}
+		GradingSchemeID: scheme.SchemeID,
+		GradesSalt:      salt,
+	}
 
-		// Get actual student record
-		studentBytes, err := ctx.GetStub().GetState(rollNumber)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read student %s: %v", rollNumber, err)
-		}
-		if studentBytes == nil {
-			continue
-		}
+	// Store with primary key; grades go to the department's private collection
+	err = s.putAcademicRecord(ctx, &record)
+	if err != nil {
+		return err
+	}
 
-		var student Student
-		err = json.Unmarshal(studentBytes, &student)
+	// Link the superseded record to its replacement
+	if replaced != nil {
+		previous, err := s.resolveRecordGrades(ctx, replaced)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
+			return err
+		}
+		previous.SupersededBy = recordID
+		if err := s.putAcademicRecord(ctx, previous); err != nil {
+			return err
 		}
-
-		students = append(students, &student)
 	}
 
-	result := &PaginatedQueryResult{
-		Records:     students,
-		Bookmark:    metadata.Bookmark,
-		RecordCount: int(metadata.FetchedRecordsCount),
-		HasMore:     metadata.Bookmark != "",
+	// Create composite keys for efficient querying
+	// 1. student~record
+	recordKey, err := ctx.GetStub().CreateCompositeKey(StudentRecordKey, []string{rollNumber, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key for student record: %w", err)
 	}
-
-	return result, nil
-}
-
-// QueryStudentsByStatus returns students by status with pagination
-func (s *SmartContract) QueryStudentsByStatus(ctx contractapi.TransactionContextInterface, status string, bookmark string, pageSize int) (*PaginatedQueryResult, error) {
-	// Validate page size
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50
+	err = ctx.GetStub().PutState(recordKey, []byte{0x00}) // Use a null byte as value
+	if err != nil {
+		return fmt.Errorf("failed to put state for student record key: %w", err)
 	}
 
-	// Validate status
-	err := validateStatus(status)
+	// 2. record~semester~{Semester}~{StudentID}~{RecordID}
+	semKey, err := ctx.GetStub().CreateCompositeKey(RecordSemesterKey, []string{fmt.Sprintf("%d", semester), rollNumber, recordID})
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to create composite key for semester record: %w", err)
 	}
-
-	// Query using composite key: student~status~{Status}~{RollNumber}
-	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(StudentStatusKey, []string{status}, int32(pageSize), bookmark)
+	err = ctx.GetStub().PutState(semKey, []byte{0x00}) // Use a null byte as value
 	if err != nil {
-		return nil, fmt.Errorf("failed to query students by status: %w", err)
+		return fmt.Errorf("failed to put state for semester record key: %w", err)
 	}
-	defer resultsIterator.Close()
-
-	var students []*Student
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate query results: %v", err)
-		}
-
-		// Extract student roll number from composite key
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %v", err)
-		}
-		rollNumber := compositeKeyParts[1] // student~status~{Status}~{RollNumber}
 
-		// Get actual student record
-		studentBytes, err := ctx.GetStub().GetState(rollNumber)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read student %s: %v", rollNumber, err)
-		}
-		if studentBytes == nil {
-			continue
-		}
+	// 3. record~status~{Status}~{StudentID}~{RecordID}
+	statusKey, err := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{StatusDraft, rollNumber, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key for status record: %w", err)
+	}
+	err = ctx.GetStub().PutState(statusKey, []byte{0x00}) // Use a null byte as value
+	if err != nil {
+		return fmt.Errorf("failed to put state for status record key: %w", err)
+	}
 
-		var student Student
-		err = json.Unmarshal(studentBytes, &student)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
-		}
+	// 4. record~department
+	deptKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptKey, []string{department, rollNumber, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key for department record: %w", err)
+	}
+	err = ctx.GetStub().PutState(deptKey, []byte{0x00}) // Use a null byte as value
+	if err != nil {
+		return fmt.Errorf("failed to put state for department record key: %w", err)
+	}
 
-		students = append(students, &student)
+	// 5. record~dept~year~{Department}~{AcademicYear}~{StudentID}~{RecordID}
+	yearKey, err := ctx.GetStub().CreateCompositeKey(RecordDeptYearKey, []string{department, year, rollNumber, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key for year record: %w", err)
+	}
+	err = ctx.GetStub().PutState(yearKey, []byte{0x00}) // Use a null byte as value
+	if err != nil {
+		return fmt.Errorf("failed to put state for year record key: %w", err)
 	}
 
-	result := &PaginatedQueryResult{
-		Records:     students,
-		Bookmark:    metadata.Bookmark,
-		RecordCount: int(metadata.FetchedRecordsCount),
-		HasMore:     metadata.Bookmark != "",
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"recordID":     recordID,
+		"rollNumber":   rollNumber,
+		"semester":     semester,
+		"year":         year,
+		"department":   department,
+		"coursesCount": len(courses),
+		"schemeId":     scheme.SchemeID,
+		"supersedes":   supersedes,
+		"status":       record.Status,
+		"gradesHash":   record.GradesHash,
+		"submittedBy":  clientID,
+		"timestamp":    timestamp.Format("2006-01-02T15:04:05Z07:00"),
 	}
+	eventJSONBytes, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordCreated", eventJSONBytes)
 
-	return result, nil
+	return nil
 }
 
-// QueryRecordsBySemester returns academic records by semester with pagination
-func (s *SmartContract) QueryRecordsBySemester(ctx contractapi.TransactionContextInterface, semester int, bookmark string, pageSize int) (*PaginatedQueryResult, error) {
-	// Validate page size
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50
-	}
-
-	// Validate semester
-	err := validateSemester(semester)
+// GetAcademicRecord retrieves an academic record (Enhanced with department access control)
+func (s *SmartContract) GetAcademicRecord(ctx contractapi.TransactionContextInterface, recordID string) (*AcademicRecord, error) {
+	record, err := s.readAcademicRecord(ctx, recordID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Query using composite key: record~semester~{Semester}~{StudentID}~{RecordID}
-	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(RecordSemesterKey, []string{fmt.Sprintf("%d", semester)}, int32(pageSize), bookmark)
+	// Access Control: department, student or consent based
+	err = s.authorizeRecordRead(ctx, record)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query records by semester: %w", err)
+		return nil, err
 	}
-	defer resultsIterator.Close()
-
-	var records []*AcademicRecord
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate query results: %v", err)
-		}
 
-		// Extract record ID from composite key
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %v", err)
-		}
-		recordID := compositeKeyParts[2] // record~semester~{Semester}~{StudentID}~{RecordID}
+	return s.viewAcademicRecord(ctx, record)
+}
 
-		// Get actual record
-		recordBytes, err := ctx.GetStub().GetState(recordID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read record %s: %v", recordID, err)
-		}
-		if recordBytes == nil {
-			continue
-		}
+// gradesCollection returns the private data collection holding a department's unreleased
+// grades. collections_config.json declares one collection per department; the collections
+// are held by NITWarangalMSP peers only, since every department shares DepartmentsMSP and a
+// collection policy cannot tell them apart. Departments reach their grades through the
+// transactions, which check department ownership.
+func gradesCollection(department string) string {
+	return "gradesCollection" + strings.ToUpper(department)
+}
 
-		var record AcademicRecord
-		err = json.Unmarshal(recordBytes, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal record: %v", err)
-		}
+// checkGradesCollection fails unless the department's grades collection is defined in the
+// chaincode's collection configuration
+func checkGradesCollection(ctx contractapi.TransactionContextInterface, department string) error {
+	collection := gradesCollection(department)
+	// Reading a hash needs no membership but fails for a collection that is not defined
+	if _, err := ctx.GetStub().GetPrivateDataHash(collection, department); err != nil {
+		return fmt.Errorf("private data collection %s is not configured: add it to collections_config.json and upgrade the chaincode first: %w", collection, err)
+	}
+	return nil
+}
 
-		// Check department access
-		err = checkDepartmentAccess(ctx, record.Department)
-		if err != nil {
-			continue // Skip records from other departments
-		}
+// minGradesSaltLength is the number of random bytes a client passes as the "salt" transient
+// field when it creates an academic record
+const minGradesSaltLength = 16
 
-		records = append(records, &record)
+// gradesSalt reads the salt for a record's private grades from transient data, hex encoded.
+// The client generates it so that every endorser writes the same private record.
+func gradesSalt(ctx contractapi.TransactionContextInterface) (string, error) {
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", fmt.Errorf("failed to get transient map: %w", err)
 	}
-
-	result := &PaginatedQueryResult{
-		Records:     records,
-		Bookmark:    metadata.Bookmark,
-		RecordCount: int(metadata.FetchedRecordsCount),
-		HasMore:     metadata.Bookmark != "",
+	salt := transientMap["salt"]
+	if len(salt) < minGradesSaltLength {
+		return "", fmt.Errorf("a random salt of at least %d bytes must be provided in transient data", minGradesSaltLength)
 	}
-
-	return result, nil
+	return hex.EncodeToString(salt), nil
 }
 
-// QueryRecordsByStatus returns academic records by status with pagination
-func (s *SmartContract) QueryRecordsByStatus(ctx contractapi.TransactionContextInterface, status string, bookmark string, pageSize int) (*PaginatedQueryResult, error) {
-	// Validate page size
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50
-	}
+// isRecordReleased reports whether a record's grades belong in public state. FINALIZED is the
+// end of the multi-party workflow; APPROVED is the end of the single-step ApproveAcademicRecord
+// path, which publishes the student's CGPA, so its grades are official as well.
+func isRecordReleased(status string) bool {
+	return status == RecordFinalized || status == RecordApproved
+}
 
-	// Validate status
-	validStatuses := []string{StatusDraft, RecordSubmitted, RecordApproved}
-	isValid := false
-	for _, validStatus := range validStatuses {
-		if status == validStatus {
-			isValid = true
-			break
-		}
+// readRecordState loads the public state of an academic record: the full record once it
+// is released, otherwise a stub carrying the hash of the private record
+func (s *SmartContract) readRecordState(ctx contractapi.TransactionContextInterface, recordID string) (*AcademicRecord, error) {
+	recordJSON, err := getAssetState(ctx, DocTypeRecord, recordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read record: %v", err)
 	}
-	if !isValid {
-		return nil, fmt.Errorf("invalid record status: %s (must be DRAFT, SUBMITTED, or APPROVED)", status)
+	if recordJSON == nil {
+		return nil, fmt.Errorf("record %s does not exist", recordID)
 	}
 
-	// Query using composite key: record~status~{Status}~{StudentID}~{RecordID}
-	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(RecordStatusKey, []string{status}, int32(pageSize), bookmark)
+	var record AcademicRecord
+	err = json.Unmarshal(recordJSON, &record)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query records by status: %w", err)
+		return nil, err
 	}
-	defer resultsIterator.Close()
-
-	var records []*AcademicRecord
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate query results: %v", err)
-		}
-
-		// Extract record ID from composite key
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			return nil, fmt.Errorf("failed to split composite key: %v", err)
-		}
-		recordID := compositeKeyParts[2] // record~status~{Status}~{StudentID}~{RecordID}
-
-		// Get actual record
-		recordBytes, err := ctx.GetStub().GetState(recordID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read record %s: %v", recordID, err)
-		}
-		if recordBytes == nil {
-			continue
-		}
-
-		var record AcademicRecord
-		err = json.Unmarshal(recordBytes, &record)
-		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal record: %v", err)
-		}
 
-		// Check department access
-		err = checkDepartmentAccess(ctx, record.Department)
-		if err != nil {
-			continue // Skip records from other departments
-		}
+	return &record, nil
+}
 
-		records = append(records, &record)
+// resolveRecordGrades replaces a public stub with the private record it stands for,
+// checking the private copy against the hash in public state
+func (s *SmartContract) resolveRecordGrades(ctx contractapi.TransactionContextInterface, stub *AcademicRecord) (*AcademicRecord, error) {
+	if stub.GradesCollection == "" {
+		return stub, nil
 	}
 
-	result := &PaginatedQueryResult{
-		Records:     records,
-		Bookmark:    metadata.Bookmark,
-		RecordCount: int(metadata.FetchedRecordsCount),
-		HasMore:     metadata.Bookmark != "",
+	privateJSON, err := ctx.GetStub().GetPrivateData(stub.GradesCollection, stub.RecordID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read grades for record %s: %v", stub.RecordID, err)
+	}
+	if privateJSON == nil {
+		return nil, fmt.Errorf("grades for record %s are not available on this peer", stub.RecordID)
+	}
+	hash := sha256.Sum256(privateJSON)
+	if hex.EncodeToString(hash[:]) != stub.GradesHash {
+		return nil, fmt.Errorf("grades for record %s do not match the hash on the ledger", stub.RecordID)
 	}
 
-	return result, nil
+	var record AcademicRecord
+	if err := json.Unmarshal(privateJSON, &record); err != nil {
+		return nil, err
+	}
+	record.GradesHash = stub.GradesHash
+	return &record, nil
 }
 
-// QueryPendingRecords returns all records awaiting approval (DRAFT + SUBMITTED)
-func (s *SmartContract) QueryPendingRecords(ctx contractapi.TransactionContextInterface, bookmark string, pageSize int) (*PaginatedQueryResult, error) {
-	// Validate page size
-	if pageSize <= 0 || pageSize > 100 {
-		pageSize = 50
+// readAcademicRecord loads an academic record, including unreleased grades, without access control
+func (s *SmartContract) readAcademicRecord(ctx contractapi.TransactionContextInterface, recordID string) (*AcademicRecord, error) {
+	record, err := s.readRecordState(ctx, recordID)
+	if err != nil {
+		return nil, err
 	}
+	return s.resolveRecordGrades(ctx, record)
+}
 
-	// Query DRAFT records first
-	var allRecords []*AcademicRecord
+// viewAcademicRecord returns the part of a record the caller may see. Unreleased grades are
+// visible to the administration and departments only; students and verifiers get the stub.
+func (s *SmartContract) viewAcademicRecord(ctx contractapi.TransactionContextInterface, record *AcademicRecord) (*AcademicRecord, error) {
+	if record.GradesCollection == "" {
+		return record, nil
+	}
 
-	// Get DRAFT records
-	draftIterator, _, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
-		RecordStatusKey,
-		[]string{StatusDraft},
-		int32(pageSize),
-		bookmark,
-	)
+	caller, err := getCaller(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query draft records: %v", err)
+		return nil, err
 	}
-	defer draftIterator.Close()
-
-	for draftIterator.HasNext() {
-		queryResponse, err := draftIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate draft records: %v", err)
-		}
+	if caller.isStudent() || caller.MSPID == VerifiersMSP {
+		return record, nil
+	}
+	return s.resolveRecordGrades(ctx, record)
+}
 
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+// putAcademicRecord stores an academic record. Released records are written to public state;
+// until then the full record goes to the department's grades collection and public state
+// keeps a stub with its hash, enough for the indexes and the approval workflow. The salt stays
+// with the private record and is dropped on release. Records created before salting take the
+// salt of a transaction that passes one.
+func (s *SmartContract) putAcademicRecord(ctx contractapi.TransactionContextInterface, record *AcademicRecord) error {
+	previousCollection := record.GradesCollection
+
+	if isRecordReleased(record.Status) {
+		record.GradesCollection = ""
+		record.GradesHash = ""
+		record.GradesSalt = ""
+		recordJSON, err := json.Marshal(record)
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to marshal record: %w", err)
 		}
-		recordID := compositeKeyParts[2]
-
-		recordBytes, err := ctx.GetStub().GetState(recordID)
-		if err != nil || recordBytes == nil {
-			continue
+		if err := putAssetState(ctx, DocTypeRecord, record.RecordID, recordJSON); err != nil {
+			return fmt.Errorf("failed to put record state: %w", err)
 		}
-
-		var record AcademicRecord
-		err = json.Unmarshal(recordBytes, &record)
-		if err != nil {
-			continue
+		if previousCollection != "" {
+			if err := ctx.GetStub().DelPrivateData(previousCollection, record.RecordID); err != nil {
+				return fmt.Errorf("failed to delete private grades for record %s: %w", record.RecordID, err)
+			}
 		}
+		return nil
+	}
 
-		// Check department access
-		err = checkDepartmentAccess(ctx, record.Department)
-		if err != nil {
-			continue
+	if record.GradesSalt == "" {
+		if salt, err := gradesSalt(ctx); err == nil {
+			record.GradesSalt = salt
 		}
-
-		allRecords = append(allRecords, &record)
 	}
-
-	// Get SUBMITTED records
-	submittedIterator, responseMetadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(
-		RecordStatusKey,
-		[]string{RecordSubmitted},
-		int32(pageSize),
-		bookmark,
-	)
+	collection := gradesCollection(record.Department)
+	record.GradesCollection = collection
+	record.GradesHash = ""
+	privateJSON, err := json.Marshal(record)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query submitted records: %v", err)
+		return fmt.Errorf("failed to marshal record: %w", err)
 	}
-	defer submittedIterator.Close()
-
-	for submittedIterator.HasNext() {
-		queryResponse, err := submittedIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate submitted records: %v", err)
-		}
-
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			continue
-		}
-		recordID := compositeKeyParts[2]
-
-		recordBytes, err := ctx.GetStub().GetState(recordID)
-		if err != nil || recordBytes == nil {
-			continue
-		}
-
-		var record AcademicRecord
-		err = json.Unmarshal(recordBytes, &record)
-		if err != nil {
-			continue
+	if err := ctx.GetStub().PutPrivateData(collection, record.RecordID, privateJSON); err != nil {
+		return fmt.Errorf("failed to put grades in %s: %w", collection, err)
+	}
+	if previousCollection != "" && previousCollection != collection {
+		if err := ctx.GetStub().DelPrivateData(previousCollection, record.RecordID); err != nil {
+			return fmt.Errorf("failed to delete private grades for record %s: %w", record.RecordID, err)
 		}
+	}
 
-		// Check department access
-		err = checkDepartmentAccess(ctx, record.Department)
-		if err != nil {
-			continue
-		}
+	hash := sha256.Sum256(privateJSON)
+	record.GradesHash = hex.EncodeToString(hash[:])
 
-		allRecords = append(allRecords, &record)
+	stub := AcademicRecord{
+		DocType:          DocTypeRecord,
+		RecordID:         record.RecordID,
+		StudentID:        record.StudentID,
+		Department:       record.Department,
+		Semester:         record.Semester,
+		AcademicYear:     record.AcademicYear,
+		Timestamp:        record.Timestamp,
+		SubmittedBy:      record.SubmittedBy,
+		ApprovedBy:       record.ApprovedBy,
+		Status:           record.Status,
+		RejectionNote:    record.RejectionNote,
+		Supersedes:       record.Supersedes,
+		SupersededBy:     record.SupersededBy,
+		SupersessionNote: record.SupersessionNote,
+		GradingSchemeID:  record.GradingSchemeID,
+		GradesCollection: collection,
+		GradesHash:       record.GradesHash,
 	}
-
-	result := &PaginatedQueryResult{
-		Records:     allRecords,
-		Bookmark:    responseMetadata.Bookmark,
-		RecordCount: len(allRecords),
-		HasMore:     responseMetadata.Bookmark != "",
+	stubJSON, err := json.Marshal(stub)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record stub: %w", err)
 	}
-
-	return result, nil
+	if err := putAssetState(ctx, DocTypeRecord, record.RecordID, stubJSON); err != nil {
+		return fmt.Errorf("failed to put record state: %w", err)
+	}
+	return nil
 }
 
-// ==================== Department Management ====================
-
-// CreateDepartment creates a new department
-func (s *SmartContract) CreateDepartment(ctx contractapi.TransactionContextInterface,
-	departmentID, departmentName, hod, email, phone string) error {
-
-	// Access Control: Only admin can create departments
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
-	}
-
-	if clientMSPID != NITWarangalMSP {
-		return fmt.Errorf("unauthorized: only admin can create departments")
-	}
-
-	// Normalize department ID to uppercase
-	departmentID = strings.ToUpper(departmentID)
-
-	// Check if department already exists
-	exists, err := s.departmentExists(ctx, departmentID)
+// ApproveAcademicRecord approves an academic record and calculates CGPA (Enhanced with RBAC and workflow)
+func (s *SmartContract) ApproveAcademicRecord(ctx contractapi.TransactionContextInterface, recordID string) error {
+	// Get record
+	record, err := s.readAcademicRecord(ctx, recordID)
 	if err != nil {
 		return err
 	}
-	if exists {
-		return fmt.Errorf("department %s already exists", departmentID)
-	}
-
-	clientID, err := ctx.GetClientIdentity().GetID()
-	if err != nil {
-		return fmt.Errorf("failed to get client ID: %v", err)
-	}
 
-	department := Department{
-		DepartmentID:   departmentID,
-		DepartmentName: departmentName,
-		HOD:            hod,
-		Email:          email,
-		Phone:          phone,
-		CreatedBy:      clientID,
-		CreatedAt:      time.Now(),
-		ModifiedBy:     clientID,
-		ModifiedAt:     time.Now(),
+	// Check if already approved
+	if record.Status == RecordApproved {
+		return fmt.Errorf("record %s is already approved", recordID)
 	}
 
-	departmentJSON, err := json.Marshal(department)
-	if err != nil {
-		return fmt.Errorf("failed to marshal department: %v", err)
+	// Record must be submitted before approval
+	if record.Status != RecordSubmitted && record.Status != RecordDraft {
+		return fmt.Errorf("cannot approve record with status %s", record.Status)
 	}
 
-	err = ctx.GetStub().PutState(departmentID, departmentJSON)
+	// Recompute the CGPA over the student's released records including this one; this sets
+	// the record's CGPA and updates the records of later semesters
+	newCGPA, totalCredits, err := s.releaseRecordGPA(ctx, record)
 	if err != nil {
-		return fmt.Errorf("failed to put department state: %v", err)
+		return fmt.Errorf("failed to calculate CGPA: %w", err)
 	}
 
-	// Create composite key for querying all departments
-	deptIndexKey, err := ctx.GetStub().CreateCompositeKey(DepartmentAllKey, []string{departmentID})
+	// Update student's overall CGPA and total credits
+	student, err := s.readStudent(ctx, record.StudentID)
 	if err != nil {
-		return fmt.Errorf("failed to create composite key: %v", err)
+		return fmt.Errorf("failed to get student for CGPA update: %w", err)
 	}
-
-	err = ctx.GetStub().PutState(deptIndexKey, []byte{0x00})
+	student.CurrentCGPA = newCGPA
+	student.TotalCreditsEarned = totalCredits
+	studentJSON, err := json.Marshal(student)
 	if err != nil {
-		return fmt.Errorf("failed to put department index: %v", err)
+		return fmt.Errorf("failed to marshal student for CGPA update: %w", err)
 	}
-
-	return nil
-}
-
-// GetDepartment retrieves a department by ID
-func (s *SmartContract) GetDepartment(ctx contractapi.TransactionContextInterface, departmentID string) (*Department, error) {
-	// Normalize department ID to uppercase
-	departmentID = strings.ToUpper(departmentID)
-
-	departmentJSON, err := ctx.GetStub().GetState(departmentID)
+	err = putAssetState(ctx, DocTypeStudent, student.RollNumber, studentJSON)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read department: %v", err)
-	}
-	if departmentJSON == nil {
-		return nil, fmt.Errorf("department %s does not exist", departmentID)
+		return fmt.Errorf("failed to update student with new CGPA: %w", err)
 	}
 
-	var department Department
-	err = json.Unmarshal(departmentJSON, &department)
+	// Get approver identity
+	approverID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal department: %v", err)
+		return fmt.Errorf("failed to get approver ID: %w", err)
 	}
 
-	return &department, nil
-}
-
-// GetAllDepartments retrieves all departments
-func (s *SmartContract) GetAllDepartments(ctx contractapi.TransactionContextInterface) ([]*Department, error) {
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(DepartmentAllKey, []string{})
+	// Get transaction timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get departments: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
 	}
-	defer resultsIterator.Close()
+	timestamp := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	var departments []*Department
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
+	// Update composite keys if status changed
+	oldStatus := record.Status
+	if oldStatus != RecordApproved {
+		// Remove old status key
+		oldStatusKey, err := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{oldStatus, record.StudentID, recordID})
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate departments: %v", err)
+			return fmt.Errorf("failed to create old status composite key for deletion: %w", err)
 		}
-
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		err = ctx.GetStub().DelState(oldStatusKey)
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to delete old status composite key: %w", err)
 		}
 
-		departmentID := compositeKeyParts[0]
-		departmentJSON, err := ctx.GetStub().GetState(departmentID)
-		if err != nil || departmentJSON == nil {
-			continue
+		// Add new status key
+		newStatusKey, err := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{RecordApproved, record.StudentID, recordID})
+		if err != nil {
+			return fmt.Errorf("failed to create new status composite key: %w", err)
 		}
-
-		var department Department
-		err = json.Unmarshal(departmentJSON, &department)
+		err = ctx.GetStub().PutState(newStatusKey, []byte{0x00})
 		if err != nil {
-			continue
+			return fmt.Errorf("failed to put new status composite key: %w", err)
 		}
-
-		departments = append(departments, &department)
 	}
 
-	return departments, nil
-}
-
-// UpdateDepartment updates department information
-func (s *SmartContract) UpdateDepartment(ctx contractapi.TransactionContextInterface,
-	departmentID string, updateData string) error {
+	// Update record
+	record.Status = RecordApproved
+	record.ApprovedBy = approverID
+	record.Timestamp = timestamp // Update timestamp to approval time
 
-	// Access Control: Only admin can update departments
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	err = s.putAcademicRecord(ctx, record)
 	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
+		return fmt.Errorf("failed to put approved record state: %w", err)
 	}
 
-	if clientMSPID != NITWarangalMSP {
-		return fmt.Errorf("unauthorized: only admin can update departments")
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"recordID":    recordID,
+		"studentID":   record.StudentID,
+		"semester":    record.Semester,
+		"department":  record.Department,
+		"sgpa":        record.SGPA,
+		"cgpa":        record.CGPA,
+		"studentCGPA": newCGPA,
+		"approvedBy":  approverID,
+		"timestamp":   time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos)).Format("2006-01-02T15:04:05Z07:00"),
 	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordApproved", eventJSON)
 
-	department, err := s.GetDepartment(ctx, departmentID)
+	return nil
+}
+
+// IssueCertificate issues a certificate with PDF hash (Enhanced with validation and RBAC)
+func (s *SmartContract) IssueCertificate(ctx contractapi.TransactionContextInterface,
+	certificateID, studentID, certType, pdfBase64, ipfsHash string) error {
+
+	// Validate certificate type
+	err := validateCertificateType(certType)
 	if err != nil {
 		return err
 	}
 
-	var updates map[string]interface{}
-	err = json.Unmarshal([]byte(updateData), &updates)
+	// Check if certificate already exists
+	existingCert, err := getAssetState(ctx, DocTypeCertificate, certificateID)
 	if err != nil {
-		return fmt.Errorf("failed to parse update data: %v", err)
-	}
-
-	// Apply updates
-	if hod, ok := updates["hod"].(string); ok {
-		department.HOD = hod
-	}
-	if email, ok := updates["email"].(string); ok {
-		department.Email = email
+		return fmt.Errorf("failed to check certificate existence: %v", err)
 	}
-	if phone, ok := updates["phone"].(string); ok {
-		department.Phone = phone
+	if existingCert != nil {
+		return fmt.Errorf("certificate %s already exists", certificateID)
 	}
 
-	clientID, err := ctx.GetClientIdentity().GetID()
+	// Verify student exists and may receive this certificate type
+	student, err := s.readStudent(ctx, studentID)
 	if err != nil {
-		return fmt.Errorf("failed to get client ID: %v", err)
+		return err
+	}
+	if err := s.checkStudentStatusFor(ctx, "IssueCertificate:"+certType, student, 0); err != nil {
+		return err
 	}
 
-	department.ModifiedBy = clientID
-	department.ModifiedAt = time.Now()
+	// Calculate hash of PDF
+	hash := sha256.Sum256([]byte(pdfBase64))
+	pdfHash := hex.EncodeToString(hash[:])
 
-	departmentJSON, err := json.Marshal(department)
+	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return fmt.Errorf("failed to marshal department: %v", err)
+		return fmt.Errorf("failed to get client identity: %v", err)
 	}
 
-	return ctx.GetStub().PutState(departmentID, departmentJSON)
-}
-
-// departmentExists checks if a department exists
-func (s *SmartContract) departmentExists(ctx contractapi.TransactionContextInterface, departmentID string) (bool, error) {
-	// Normalize department ID to uppercase
-	departmentID = strings.ToUpper(departmentID)
-
-	departmentJSON, err := ctx.GetStub().GetState(departmentID)
+	// Get transaction timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
 	if err != nil {
-		return false, fmt.Errorf("failed to read department: %v", err)
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
 	}
-	return departmentJSON != nil, nil
-}
-
-// ==================== Course Offering Management ====================
-
-// CreateCourseOffering creates a new course offering (many-to-many relationship)
-func (s *SmartContract) CreateCourseOffering(ctx contractapi.TransactionContextInterface,
-	departmentID, courseCode, courseName string, credits float64, semester int, academicYear string) error {
+	issueDate := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Access Control: Department or Admin can create course offerings
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
-	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
+	// Set expiry date for BONAFIDE certificates (6 months)
+	var expiryDate time.Time
+	if certType == CertBonafide {
+		expiryDate = issueDate.AddDate(0, 6, 0) // 6 months validity
 	}
 
-	if clientMSPID != DepartmentsMSP && clientMSPID != NITWarangalMSP {
-		return fmt.Errorf("unauthorized: only department or admin can create course offerings")
+	// Calculate degree name based on department
+	degreeAwarded := ""
+	if certType == CertDegree || certType == CertProvisional {
+		degreeAwarded = fmt.Sprintf("B.Tech in %s", student.Department)
 	}
 
-	// Normalize department ID to uppercase
-	departmentID = strings.ToUpper(departmentID)
+	// Get final CGPA from student record
+	finalCGPA := student.CurrentCGPA
 
-	// Verify department exists
-	exists, err := s.departmentExists(ctx, departmentID)
-	if err != nil {
-		return err
-	}
-	if !exists {
-		return fmt.Errorf("department %s does not exist", departmentID)
+	// Calculate isValid: not revoked and not expired
+	isValid := true // Initial state, will be computed dynamically in GetCertificate
+
+	certificate := Certificate{
+		DocType:       DocTypeCertificate,
+		CertificateID: certificateID,
+		StudentID:     studentID,
+		Type:          certType,
+		IssueDate:     issueDate,
+		ExpiryDate:    expiryDate,
+		PDFHash:       pdfHash,
+		IPFSHash:      ipfsHash,
+		IssuedBy:      clientID,
+		Verified:      true,
+		Revoked:       false,
+		DegreeAwarded: degreeAwarded,
+		FinalCGPA:     finalCGPA,
+		IsValid:       isValid,
 	}
 
-	// Validate credits
-	if err := validateCredits(credits); err != nil {
+	certJSON, err := json.Marshal(certificate)
+	if err != nil {
 		return err
 	}
 
-	// Validate semester
-	if err := validateSemester(semester); err != nil {
+	err = putAssetState(ctx, DocTypeCertificate, certificateID, certJSON)
+	if err != nil {
 		return err
 	}
 
-	// Create unique offering ID
-	offeringID := fmt.Sprintf("%s-%s-%d-%s", departmentID, courseCode, semester, academicYear)
-
-	// Check if offering already exists
-	offeringJSON, err := ctx.GetStub().GetState(offeringID)
+	// Create composite key for student certificates
+	certKey, err := ctx.GetStub().CreateCompositeKey(CertStudentKey, []string{studentID, certificateID})
 	if err != nil {
-		return fmt.Errorf("failed to read offering: %v", err)
-	}
-	if offeringJSON != nil {
-		return fmt.Errorf("course offering %s already exists", offeringID)
+		return fmt.Errorf("failed to create composite key for certificate: %w", err)
 	}
-
-	clientID, err := ctx.GetClientIdentity().GetID()
+	err = ctx.GetStub().PutState(certKey, []byte{0x00})
 	if err != nil {
-		return fmt.Errorf("failed to get client ID: %v", err)
+		return err
 	}
 
-	offering := CourseOffering{
-		OfferingID:   offeringID,
-		DepartmentID: departmentID,
-		CourseCode:   courseCode,
-		CourseName:   courseName,
-		Credits:      credits,
-		Semester:     semester,
-		AcademicYear: academicYear,
-		IsActive:     true,
-		CreatedBy:    clientID,
-		CreatedAt:    time.Now(),
-		ModifiedBy:   clientID,
-		ModifiedAt:   time.Now(),
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"certificateID": certificateID,
+		"studentID":     studentID,
+		"type":          certType,
+		"issuedBy":      clientID,
+		"issueDate":     issueDate.Format("2006-01-02T15:04:05Z07:00"),
 	}
-
-	offeringJSON, err = json.Marshal(offering)
-	if err != nil {
-		return fmt.Errorf("failed to marshal offering: %v", err)
+	if !expiryDate.IsZero() {
+		eventPayload["expiryDate"] = expiryDate.Format("2006-01-02T15:04:05Z07:00")
 	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("CertificateIssued", eventJSON)
+
+	return nil
+}
+
+// GetCertificate retrieves a certificate (Enhanced with revocation check)
+func (s *SmartContract) GetCertificate(ctx contractapi.TransactionContextInterface,
+	certificateID string) (*Certificate, error) {
 
-	err = ctx.GetStub().PutState(offeringID, offeringJSON)
+	certJSON, err := getAssetState(ctx, DocTypeCertificate, certificateID)
 	if err != nil {
-		return fmt.Errorf("failed to put offering state: %v", err)
+		return nil, fmt.Errorf("failed to read certificate: %v", err)
+	}
+	if certJSON == nil {
+		return nil, fmt.Errorf("certificate %s does not exist", certificateID)
 	}
 
-	// Create composite key for querying by department
-	deptCourseKey, err := ctx.GetStub().CreateCompositeKey(CourseDeptKey, []string{departmentID, offeringID})
+	var certificate Certificate
+	err = json.Unmarshal(certJSON, &certificate)
 	if err != nil {
-		return fmt.Errorf("failed to create composite key: %v", err)
+		return nil, err
 	}
 
-	err = ctx.GetStub().PutState(deptCourseKey, []byte{0x00})
-	if err != nil {
-		return fmt.Errorf("failed to put course-dept index: %v", err)
+	if err := s.authorizeStudentReadByID(ctx, certificate.StudentID); err != nil {
+		return nil, err
 	}
 
-	return nil
-}
+	// Get current time for expiry check
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	currentTime := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-// GetCourseOffering retrieves a course offering by ID
-func (s *SmartContract) GetCourseOffering(ctx contractapi.TransactionContextInterface, offeringID string) (*CourseOffering, error) {
-	offeringJSON, err := ctx.GetStub().GetState(offeringID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read course offering: %v", err)
-	}
-	if offeringJSON == nil {
-		return nil, fmt.Errorf("course offering %s does not exist", offeringID)
-	}
+	// Dynamically compute IsValid: not revoked AND (no expiry OR not expired)
+	certificate.IsValid = !certificate.Revoked &&
+		(certificate.ExpiryDate.IsZero() || currentTime.Before(certificate.ExpiryDate))
 
-	var offering CourseOffering
-	err = json.Unmarshal(offeringJSON, &offering)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal course offering: %v", err)
+	// Check if certificate is expired (for BONAFIDE certificates)
+	if certificate.Type == CertBonafide && !certificate.ExpiryDate.IsZero() {
+		if currentTime.After(certificate.ExpiryDate) {
+			certificate.Verified = false // Mark as not verified if expired
+		}
 	}
 
-	return &offering, nil
+	return &certificate, nil
 }
 
-// GetCoursesByDepartment retrieves all courses offered by a department
-func (s *SmartContract) GetCoursesByDepartment(ctx contractapi.TransactionContextInterface, departmentID string) ([]*CourseOffering, error) {
-	// Normalize department ID to uppercase
-	departmentID = strings.ToUpper(departmentID)
+// VerifyCertificate verifies a certificate by comparing PDF hash (Enhanced with revocation and expiry check)
+// Open to any caller: presenting the PDF is what entitles a verifier to the answer.
+func (s *SmartContract) VerifyCertificate(ctx contractapi.TransactionContextInterface,
+	certificateID, pdfBase64 string) (bool, error) {
 
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(CourseDeptKey, []string{departmentID})
+	certJSON, err := getAssetState(ctx, DocTypeCertificate, certificateID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get courses: %v", err)
+		return false, fmt.Errorf("failed to read certificate: %v", err)
+	}
+	if certJSON == nil {
+		return false, fmt.Errorf("certificate %s does not exist", certificateID)
 	}
-	defer resultsIterator.Close()
 
-	var courses []*CourseOffering
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate courses: %v", err)
-		}
+	var certificate Certificate
+	err = json.Unmarshal(certJSON, &certificate)
+	if err != nil {
+		return false, err
+	}
 
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
-		if err != nil {
-			continue
-		}
+	// Check if certificate is revoked
+	if certificate.Revoked {
+		return false, fmt.Errorf("certificate has been revoked: %s", certificate.RevocationReason)
+	}
 
-		offeringID := compositeKeyParts[1]
-		offeringJSON, err := ctx.GetStub().GetState(offeringID)
-		if err != nil || offeringJSON == nil {
-			continue
+	// Check if certificate is expired (for BONAFIDE certificates)
+	if certificate.Type == CertBonafide && !certificate.ExpiryDate.IsZero() {
+		txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+		currentTime := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+		if currentTime.After(certificate.ExpiryDate) {
+			return false, fmt.Errorf("certificate has expired on %s", certificate.ExpiryDate.Format("2006-01-02"))
 		}
+	}
 
-		var offering CourseOffering
-		err = json.Unmarshal(offeringJSON, &offering)
-		if err != nil {
-			continue
-		}
+	// Calculate hash of provided PDF
+	hash := sha256.Sum256([]byte(pdfBase64))
+	providedHash := hex.EncodeToString(hash[:])
 
-		courses = append(courses, &offering)
+	// Verify hash matches
+	if providedHash != certificate.PDFHash {
+		return false, nil
 	}
 
-	return courses, nil
+	return true, nil
 }
 
-// UpdateCourseOffering updates course offering details
-func (s *SmartContract) UpdateCourseOffering(ctx contractapi.TransactionContextInterface,
-	offeringID string, isActive bool) error {
+// RevokeCertificate revokes a certificate (NEW - with multi-party approval)
+func (s *SmartContract) RevokeCertificate(ctx contractapi.TransactionContextInterface,
+	certificateID, reason string) error {
 
-	// Access Control: Department or Admin
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+	// Get certificate
+	certJSON, err := getAssetState(ctx, DocTypeCertificate, certificateID)
 	if err != nil {
-		return fmt.Errorf("failed to get MSPID: %v", err)
+		return fmt.Errorf("failed to read certificate: %v", err)
 	}
-
-	if clientMSPID != DepartmentsMSP && clientMSPID != NITWarangalMSP {
-		return fmt.Errorf("unauthorized: only department or admin can update course offerings")
+	if certJSON == nil {
+		return fmt.Errorf("certificate %s does not exist", certificateID)
 	}
 
-	offering, err := s.GetCourseOffering(ctx, offeringID)
+	var certificate Certificate
+	err = json.Unmarshal(certJSON, &certificate)
 	if err != nil {
 		return err
 	}
 
+	// Check if already revoked
+	if certificate.Revoked {
+		return fmt.Errorf("certificate %s is already revoked", certificateID)
+	}
+
+	// Validate reason
+	if len(reason) < 10 {
+		return fmt.Errorf("revocation reason must be at least 10 characters")
+	}
+
+	// Get revoker identity
 	clientID, err := ctx.GetClientIdentity().GetID()
 	if err != nil {
-		return fmt.Errorf("failed to get client ID: %v", err)
+		return fmt.Errorf("failed to get client identity: %v", err)
 	}
 
-	offering.IsActive = isActive
-	offering.ModifiedBy = clientID
-	offering.ModifiedAt = time.Now()
+	// Get timestamp
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %v", err)
+	}
+	revokedAt := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	offeringJSON, err := json.Marshal(offering)
+	// Update certificate
+	certificate.Revoked = true
+	certificate.RevokedBy = clientID
+	certificate.RevokedAt = revokedAt
+	certificate.RevocationReason = reason
+	certificate.Verified = false
+	certificate.IsValid = false // Mark as invalid when revoked
+
+	updatedCertJSON, err := json.Marshal(certificate)
 	if err != nil {
-		return fmt.Errorf("failed to marshal offering: %v", err)
+		return err
+	}
+
+	err = putAssetState(ctx, DocTypeCertificate, certificateID, updatedCertJSON)
+	if err != nil {
+		return err
+	}
+
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"certificateID": certificateID,
+		"studentID":     certificate.StudentID,
+		"type":          certificate.Type,
+		"revokedBy":     clientID,
+		"revokedAt":     revokedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"reason":        reason,
 	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("CertificateRevoked", eventJSON)
 
-	return ctx.GetStub().PutState(offeringID, offeringJSON)
+	return nil
 }
 
-// GetStudentsByDepartment retrieves students by department (replaces GetStudentsByFaculty)
-func (s *SmartContract) GetStudentsByDepartment(ctx contractapi.TransactionContextInterface, department string) ([]*Student, error) {
-	// Normalize department to uppercase for case-insensitive matching
-	department = strings.ToUpper(department)
+// GetCertificatesByStudent retrieves a page of certificates for a student
+func (s *SmartContract) GetCertificatesByStudent(ctx contractapi.TransactionContextInterface,
+	studentID string, bookmark string, pageSize int) (*CertificatePage, error) {
 
-	// Department can view their own students
-	err := checkDepartmentAccess(ctx, department)
-	if err != nil {
-		// Allow admin to view any department
-		clientMSPID, mspErr := ctx.GetClientIdentity().GetMSPID()
-		if mspErr != nil || clientMSPID != NITWarangalMSP {
-			return nil, err
-		}
+	if err := s.authorizeStudentReadByID(ctx, studentID); err != nil {
+		return nil, err
 	}
 
-	// Use composite key instead of CouchDB query for LevelDB compatibility
-	resultsIterator, err := ctx.GetStub().GetStateByPartialCompositeKey(StudentDeptKey, []string{department})
+	// Page through certificate~student~{studentID}~{certificateID}
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: CertStudentKey, attributes: []string{studentID}}}, bookmark, pageSize)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query students: %v", err)
+		return nil, fmt.Errorf("failed to get certificates by student: %w", err)
 	}
-	defer resultsIterator.Close()
 
-	var students []*Student
-	for resultsIterator.HasNext() {
-		queryResponse, err := resultsIterator.Next()
-		if err != nil {
-			return nil, fmt.Errorf("failed to iterate students: %v", err)
-		}
+	// Get current time for expiry check
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	currentTime := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-		// Extract roll number from composite key
-		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+	certificates := []*Certificate{}
+	for _, key := range keys {
+		// Split the composite key to extract certificateID
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
 		if err != nil {
-			continue
+			return nil, err
 		}
-
 		if len(compositeKeyParts) < 2 {
 			continue
 		}
+		certificateID := compositeKeyParts[1]
 
-		rollNumber := compositeKeyParts[1] // student~dept~{Department}~{RollNumber}
-
-		// Get student record
-		studentJSON, err := ctx.GetStub().GetState(rollNumber)
-		if err != nil || studentJSON == nil {
+		// Fetch the actual certificate
+		certJSON, err := getAssetState(ctx, DocTypeCertificate, certificateID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read certificate %s: %v", certificateID, err)
+		}
+		if certJSON == nil {
 			continue
 		}
 
-		var student Student
-		err = json.Unmarshal(studentJSON, &student)
+		var certificate Certificate
+		err = json.Unmarshal(certJSON, &certificate)
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to unmarshal certificate %s: %v", certificateID, err)
 		}
 
-		students = append(students, &student)
+		// Dynamically compute IsValid: not revoked AND (no expiry OR not expired)
+		certificate.IsValid = !certificate.Revoked &&
+			(certificate.ExpiryDate.IsZero() || currentTime.Before(certificate.ExpiryDate))
+
+		certificates = append(certificates, &certificate)
 	}
 
-	return students, nil
+	return &CertificatePage{
+		Records:       certificates,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(certificates),
+		FilteredCount: len(keys) - len(certificates),
+		HasMore:       nextBookmark != "",
+	}, nil
 }
 
-// ============================================================
-// MULTI-PARTY APPROVAL WORKFLOW — New Types & Constants
-// ============================================================
-
-// Extended approval status constants (added to existing DRAFT/SUBMITTED/APPROVED)
-const (
-	RecordFacultyApproved  = "FACULTY_APPROVED"
-	RecordHODApproved      = "HOD_APPROVED"
-	RecordESLocked         = "EXAM_LOCKED"
-	RecordDeanApproved     = "DEAN_APPROVED"
-	RecordFinalized        = "FINALIZED"
-	RecordRejected         = "REJECTED"
+// GetStudentHistory retrieves a page of academic records for a student
+func (s *SmartContract) GetStudentHistory(ctx contractapi.TransactionContextInterface, studentID string, bookmark string, pageSize int) (*RecordPage, error) {
+	if err := s.authorizeStudentReadByID(ctx, studentID); err != nil {
+		return nil, err
+	}
+
+	// Page through student~record~{studentID}~{recordID}
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: StudentRecordKey, attributes: []string{studentID}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get student history: %w", err)
+	}
+
+	records := []*AcademicRecord{}
+	for _, key := range keys {
+		// Split the composite key to extract recordID
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		// compositeKeyParts[0] is studentID, compositeKeyParts[1] is recordID
+		if len(compositeKeyParts) < 2 {
+			continue
+		}
+		recordID := compositeKeyParts[1]
+
+		// Fetch the actual record using recordID
+		recordJSON, err := getAssetState(ctx, DocTypeRecord, recordID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record %s: %v", recordID, err)
+		}
+		if recordJSON == nil {
+			continue
+		}
+
+		var record AcademicRecord
+		err = json.Unmarshal(recordJSON, &record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record %s: %v", recordID, err)
+		}
+		view, err := s.viewAcademicRecord(ctx, &record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, view)
+	}
+
+	return &RecordPage{
+		Records:       records,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(records),
+		FilteredCount: len(keys) - len(records),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// GetStudentCGPA retrieves the current CGPA for a student
+func (s *SmartContract) GetStudentCGPA(ctx contractapi.TransactionContextInterface, studentID string) (float64, error) {
+	student, err := s.GetStudent(ctx, studentID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get student: %w", err)
+	}
+	return student.CurrentCGPA, nil
+}
+
+// GetAllStudents retrieves a page of students using a composite key for efficiency
+func (s *SmartContract) GetAllStudents(ctx contractapi.TransactionContextInterface, bookmark string, pageSize int) (*StudentPage, error) {
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: StudentAllKey, attributes: []string{}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all students: %w", err)
+	}
+
+	students := []*Student{}
+	for _, key := range keys {
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %w", err)
+		}
+		if len(compositeKeyParts) < 1 {
+			return nil, fmt.Errorf("invalid composite key for student")
+		}
+		rollNumber := compositeKeyParts[0]
+
+		student, err := s.readStudent(ctx, rollNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get student %s: %w", rollNumber, err)
+		}
+
+		// Only return students the caller is allowed to read
+		if s.authorizeStudentRead(ctx, student) != nil {
+			continue
+		}
+		students = append(students, student)
+	}
+
+	return &StudentPage{
+		Records:       students,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(students),
+		FilteredCount: len(keys) - len(students),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// DEPRECATED: Use GetStudentsByDepartment instead
+// GetStudentsByFaculty retrieves all students in the same department as the faculty
+// For faculty to view students in their department
+// This function is kept for backward compatibility but should not be used
+func (s *SmartContract) GetStudentsByFaculty(ctx contractapi.TransactionContextInterface, facultyID string, facultyDepartment string, bookmark string, pageSize int) (*StudentPage, error) {
+	// Redirect to GetStudentsByDepartment
+	return s.GetStudentsByDepartment(ctx, facultyDepartment, bookmark, pageSize)
+}
+
+// calculateGrades computes a semester's results under a grading scheme: the credits the SGPA
+// is weighted by, the credits earned and the SGPA, in fixed point (see GPA arithmetic).
+// Grades that do not count toward the GPA (audit or pass/fail grades) are left out of the
+// SGPA entirely.
+func calculateGrades(courses []Course, scheme *GradingScheme) (float64, float64, float64) {
+	var weightedPoints, gpaCredits, earnedCredits int64
+
+	for _, course := range courses {
+		definition, ok := scheme.lookup(course.Grade)
+		if !ok {
+			continue
+		}
+		credits := toHundredths(course.Credits)
+		if definition.CountsTowardGPA {
+			gpaCredits += credits
+			weightedPoints += toHundredths(definition.Points) * credits
+		}
+		if definition.CountsTowardCredits {
+			earnedCredits += credits
+		}
+	}
+
+	return fromHundredths(gpaCredits), fromHundredths(earnedCredits), fromHundredths(gpaQuotient(weightedPoints, gpaCredits))
+}
+
+// gpaCredits returns the credits a record's SGPA is weighted by in the CGPA. Records graded
+// before grading schemes were configurable count all their credits.
+func (record *AcademicRecord) gpaCredits() float64 {
+	if record.GradingSchemeID == "" {
+		return record.TotalCredits
+	}
+	return record.GPACredits
+}
+
+// ============================================================================
+// GPA arithmetic
+// ============================================================================
+
+// GPA math is done in fixed point so that every peer computes the same value whatever order
+// records are read in. Credits and grade points are scaled by 100 to integers (hundredths);
+// a GPA is the integer quotient of weighted points by credits, rounded half up to hundredths.
+// Stored SGPA and CGPA values therefore never carry more than two decimals.
+
+// toHundredths scales a non-negative value to whole hundredths, rounding half up
+func toHundredths(value float64) int64 {
+	return int64(value*100 + 0.5)
+}
+
+// fromHundredths converts whole hundredths back to a value
+func fromHundredths(value int64) float64 {
+	return float64(value) / 100
+}
+
+// gpaQuotient divides weighted points (hundredths of a point times hundredths of a credit) by
+// hundredths of a credit, rounding half up to hundredths of a point
+func gpaQuotient(weightedPoints, credits int64) int64 {
+	if credits <= 0 {
+		return 0
+	}
+	return (2*weightedPoints + credits) / (2 * credits)
+}
+
+// earnedCredits returns the credits a record earns. Records graded before grading schemes were
+// configurable count all their credits.
+func (record *AcademicRecord) earnedCredits() float64 {
+	if record.GradingSchemeID == "" {
+		return record.TotalCredits
+	}
+	return record.EarnedCredits
+}
+
+// cumulativeGPA is the one CGPA routine: the credit-weighted mean of the records' SGPAs under
+// the fixed-point rule. It returns the CGPA, the credits it is weighted by and the credits earned.
+func cumulativeGPA(records []*AcademicRecord) (float64, float64, float64) {
+	var weightedPoints, credits, earned int64
+	for _, record := range records {
+		recordCredits := toHundredths(record.gpaCredits())
+		weightedPoints += toHundredths(record.SGPA) * recordCredits
+		credits += recordCredits
+		earned += toHundredths(record.earnedCredits())
+	}
+	return fromHundredths(gpaQuotient(weightedPoints, credits)), fromHundredths(credits), fromHundredths(earned)
+}
+
+// studentRecords returns the public state of a student's records (stubs for unreleased
+// ones) ordered by semester, then timestamp and record ID
+func (s *SmartContract) studentRecords(ctx contractapi.TransactionContextInterface, studentID string) ([]*AcademicRecord, error) {
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(StudentRecordKey, []string{studentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records of student %s: %w", studentID, err)
+	}
+	defer iter.Close()
+
+	records := []*AcademicRecord{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate records of student %s: %w", studentID, err)
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		record, err := s.readRecordState(ctx, parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("failed to get academic record %s: %w", parts[1], err)
+		}
+		records = append(records, record)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Semester != records[j].Semester {
+			return records[i].Semester < records[j].Semester
+		}
+		if !records[i].Timestamp.Equal(records[j].Timestamp) {
+			return records[i].Timestamp.Before(records[j].Timestamp)
+		}
+		return records[i].RecordID < records[j].RecordID
+	})
+	return records, nil
+}
+
+// releasedRecords returns a student's released (APPROVED or FINALIZED) records ordered by
+// semester, then timestamp and record ID
+func (s *SmartContract) releasedRecords(ctx contractapi.TransactionContextInterface, studentID string) ([]*AcademicRecord, error) {
+	records, err := s.studentRecords(ctx, studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	released := []*AcademicRecord{}
+	for _, record := range records {
+		if isRecordReleased(record.Status) {
+			released = append(released, record)
+		}
+	}
+	return released, nil
+}
+
+// gpaRecords picks the records a CGPA is computed over from released records ordered by
+// semester: one per semester, the latest where records from before the one-record-per-semester
+// rule hold more than one
+func gpaRecords(released []*AcademicRecord) []*AcademicRecord {
+	records := []*AcademicRecord{}
+	for _, record := range released {
+		if n := len(records); n > 0 && records[n-1].Semester == record.Semester {
+			records[n-1] = record
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// applyCumulativeGPA sets the CGPA of each record, ordered by semester, to the CGPA through
+// its semester
+func applyCumulativeGPA(records []*AcademicRecord) {
+	for i, record := range records {
+		record.CGPA, _, _ = cumulativeGPA(records[:i+1])
+	}
+}
+
+// updateCGPAChain recomputes the CGPA through each semester over a student's released records,
+// ordered by semester, and stores every record whose CGPA changed except the one named by
+// releasing, which its caller stores. It returns the student's CGPA and credits earned.
+func (s *SmartContract) updateCGPAChain(ctx contractapi.TransactionContextInterface, released []*AcademicRecord, releasing string) (float64, float64, error) {
+	records := gpaRecords(released)
+	previous := make(map[string]float64, len(records))
+	for _, record := range records {
+		previous[record.RecordID] = record.CGPA
+	}
+
+	applyCumulativeGPA(records)
+	for _, record := range records {
+		if record.RecordID == releasing || record.CGPA == previous[record.RecordID] {
+			continue
+		}
+		if err := s.putAcademicRecord(ctx, record); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	cgpa, _, earned := cumulativeGPA(records)
+	return cgpa, earned, nil
+}
+
+// releaseRecordGPA computes the CGPA for a record being approved or finalized over all the
+// student's released records, whatever order they were approved in. It sets the record's
+// CGPA, updates the stored CGPA of the records of later semesters and returns the student's
+// CGPA and credits earned; the caller stores the record and the student. A semester may only
+// have one released record, so an earlier one has to be superseded first.
+func (s *SmartContract) releaseRecordGPA(ctx contractapi.TransactionContextInterface, current *AcademicRecord) (float64, float64, error) {
+	records, err := s.releasedRecords(ctx, current.StudentID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	released := []*AcademicRecord{}
+	for _, record := range records {
+		if record.RecordID == current.RecordID {
+			continue
+		}
+		if record.Semester == current.Semester {
+			return 0, 0, fmt.Errorf("semester %d of student %s already has released record %s; supersede it first",
+				current.Semester, current.StudentID, record.RecordID)
+		}
+		released = append(released, record)
+	}
+	released = append(released, current)
+	sort.SliceStable(released, func(i, j int) bool {
+		return released[i].Semester < released[j].Semester
+	})
+
+	return s.updateCGPAChain(ctx, released, current.RecordID)
+}
+
+// RecordGPAChange is a record whose stored SGPA or CGPA was corrected by RecomputeStudentCGPA
+type RecordGPAChange struct {
+	RecordID string  `json:"recordId"`
+	Semester int     `json:"semester"`
+	OldSGPA  float64 `json:"oldSGPA"`
+	NewSGPA  float64 `json:"newSGPA"`
+	OldCGPA  float64 `json:"oldCGPA"`
+	NewCGPA  float64 `json:"newCGPA"`
+}
+
+// CGPAReconciliation reports what RecomputeStudentCGPA found and corrected
+type CGPAReconciliation struct {
+	RollNumber       string            `json:"rollNumber"`
+	RecordsChecked   int               `json:"recordsChecked"`
+	OldCGPA          float64           `json:"oldCGPA"`
+	NewCGPA          float64           `json:"newCGPA"`
+	OldCreditsEarned float64           `json:"oldCreditsEarned"`
+	NewCreditsEarned float64           `json:"newCreditsEarned"`
+	Records          []RecordGPAChange `json:"records"`
+	Changed          bool              `json:"changed"`
+	TxID             string            `json:"txId"`
+}
+
+// RecomputeStudentCGPA recomputes the SGPA of each of a student's released records from its
+// courses under its grading scheme, the CGPA through each semester and the student's CGPA
+// and credits earned, and stores every value that differs from the ledger
+func (s *SmartContract) RecomputeStudentCGPA(ctx contractapi.TransactionContextInterface, rollNumber string) (*CGPAReconciliation, error) {
+	student, err := s.readStudent(ctx, rollNumber)
+	if err != nil {
+		return nil, err
+	}
+	records, err := s.releasedRecords(ctx, rollNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CGPAReconciliation{
+		RollNumber:       rollNumber,
+		RecordsChecked:   len(records),
+		OldCGPA:          student.CurrentCGPA,
+		OldCreditsEarned: student.TotalCreditsEarned,
+		Records:          []RecordGPAChange{},
+		TxID:             ctx.GetStub().GetTxID(),
+	}
+
+	// Recompute every SGPA first; the CGPAs below depend on all of them
+	oldValues := make([]RecordGPAChange, len(records))
+	for i, record := range records {
+		oldValues[i] = RecordGPAChange{RecordID: record.RecordID, Semester: record.Semester, OldSGPA: record.SGPA, OldCGPA: record.CGPA}
+		if len(record.Courses) == 0 {
+			continue
+		}
+		scheme, err := readGradingScheme(ctx, record.GradingSchemeID)
+		if err != nil {
+			return nil, err
+		}
+		record.GPACredits, record.EarnedCredits, record.SGPA = calculateGrades(record.Courses, scheme)
+		record.GradingSchemeID = scheme.SchemeID
+	}
+
+	counted := gpaRecords(records)
+	applyCumulativeGPA(counted)
+	for i, record := range records {
+		change := oldValues[i]
+		change.NewSGPA, change.NewCGPA = record.SGPA, record.CGPA
+		if change.NewSGPA == change.OldSGPA && change.NewCGPA == change.OldCGPA {
+			continue
+		}
+		if err := s.putAcademicRecord(ctx, record); err != nil {
+			return nil, err
+		}
+		report.Records = append(report.Records, change)
+	}
+
+	report.NewCGPA, _, report.NewCreditsEarned = cumulativeGPA(counted)
+	report.Changed = len(report.Records) > 0 ||
+		report.NewCGPA != report.OldCGPA || report.NewCreditsEarned != report.OldCreditsEarned
+	if report.NewCGPA != report.OldCGPA || report.NewCreditsEarned != report.OldCreditsEarned {
+		clientID, err := ctx.GetClientIdentity().GetID()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get client identity: %w", err)
+		}
+		txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction timestamp: %w", err)
+		}
+		student.CurrentCGPA = report.NewCGPA
+		student.TotalCreditsEarned = report.NewCreditsEarned
+		student.ModifiedBy = clientID
+		student.ModifiedAt = time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+		studentJSON, err := json.Marshal(student)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal student: %w", err)
+		}
+		if err := putAssetState(ctx, DocTypeStudent, student.RollNumber, studentJSON); err != nil {
+			return nil, fmt.Errorf("failed to update student with new CGPA: %w", err)
+		}
+	}
+
+	if report.Changed {
+		eventPayload := map[string]interface{}{
+			"rollNumber":     rollNumber,
+			"oldCGPA":        report.OldCGPA,
+			"newCGPA":        report.NewCGPA,
+			"recordsChanged": len(report.Records),
+		}
+		eventJSON, _ := json.Marshal(eventPayload)
+		ctx.GetStub().SetEvent("StudentCGPARecomputed", eventJSON)
+	}
+
+	return report, nil
+}
+
+// ============================================================================
+// Record supersession
+// ============================================================================
+
+// replaceableSemesterRecord checks that a student has no record for a semester other than
+// superseded ones and returns the latest superseded record not yet replaced, if any
+func (s *SmartContract) replaceableSemesterRecord(ctx contractapi.TransactionContextInterface, rollNumber string, semester int) (*AcademicRecord, error) {
+	records, err := s.studentRecords(ctx, rollNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	var replaced *AcademicRecord
+	for _, record := range records {
+		if record.Semester != semester {
+			continue
+		}
+		if record.Status != RecordSuperseded {
+			return nil, fmt.Errorf("student %s already has record %s for semester %d; supersede it before creating another",
+				rollNumber, record.RecordID, semester)
+		}
+		if record.SupersededBy == "" {
+			replaced = record
+		}
+	}
+	return replaced, nil
+}
+
+// SupersedeAcademicRecord withdraws a record so that a corrected record can be created for its
+// semester. A superseded record no longer counts toward the CGPA; if it was released, the CGPA
+// of the student and of the records of later semesters is recomputed without it.
+func (s *SmartContract) SupersedeAcademicRecord(ctx contractapi.TransactionContextInterface, recordID, reason string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if rec.Status == RecordSuperseded {
+		return fmt.Errorf("record %s is already superseded", recordID)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return fmt.Errorf("supersession reason is required")
+	}
+	wasReleased := isRecordReleased(rec.Status)
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %w", err)
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	if err := s.updateRecordStatus(ctx, recordID, RecordSuperseded); err != nil {
+		return err
+	}
+	rec.Status = RecordSuperseded
+	rec.SupersessionNote = reason
+	if err := s.putAcademicRecord(ctx, rec); err != nil {
+		return err
+	}
+
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordSuperseded
+	ar.UpdatedAt = now
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	if wasReleased {
+		records, err := s.releasedRecords(ctx, rec.StudentID)
+		if err != nil {
+			return err
+		}
+		released := []*AcademicRecord{}
+		for _, record := range records {
+			if record.RecordID != recordID {
+				released = append(released, record)
+			}
+		}
+		cgpa, earned, err := s.updateCGPAChain(ctx, released, "")
+		if err != nil {
+			return fmt.Errorf("failed to recompute CGPA: %w", err)
+		}
+
+		student, err := s.readStudent(ctx, rec.StudentID)
+		if err != nil {
+			return err
+		}
+		student.CurrentCGPA = cgpa
+		student.TotalCreditsEarned = earned
+		student.ModifiedBy = clientID
+		student.ModifiedAt = now
+		studentJSON, err := json.Marshal(student)
+		if err != nil {
+			return fmt.Errorf("failed to marshal student: %w", err)
+		}
+		if err := putAssetState(ctx, DocTypeStudent, student.RollNumber, studentJSON); err != nil {
+			return fmt.Errorf("failed to update student with new CGPA: %w", err)
+		}
+	}
+
+	eventPayload := map[string]interface{}{
+		"recordId":     recordID,
+		"studentId":    rec.StudentID,
+		"semester":     rec.Semester,
+		"wasReleased":  wasReleased,
+		"reason":       reason,
+		"supersededBy": clientID,
+		"timestamp":    now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordSuperseded", eventJSON)
+
+	return nil
+}
+
+// ============================================================================
+// Phase 2: Query Functions with Pagination
+// ============================================================================
+
+// Paginated list queries return one of the typed page envelopes below so the contract
+// metadata carries a schema for the records. RecordCount is the number of records
+// returned; FilteredCount is the number of index entries read for the page but left out
+// because the asset is missing or the caller may not read it. Pass Bookmark back to
+// fetch the next page while HasMore is set.
+
+// StudentPage is one page of students
+type StudentPage struct {
+	Records       []*Student `json:"records"`
+	Bookmark      string     `json:"bookmark"`
+	RecordCount   int        `json:"recordCount"`
+	FilteredCount int        `json:"filteredCount"`
+	HasMore       bool       `json:"hasMore"`
+}
+
+// RecordPage is one page of academic records
+type RecordPage struct {
+	Records       []*AcademicRecord `json:"records"`
+	Bookmark      string            `json:"bookmark"`
+	RecordCount   int               `json:"recordCount"`
+	FilteredCount int               `json:"filteredCount"`
+	HasMore       bool              `json:"hasMore"`
+}
+
+// CertificatePage is one page of certificates
+type CertificatePage struct {
+	Records       []*Certificate `json:"records"`
+	Bookmark      string         `json:"bookmark"`
+	RecordCount   int            `json:"recordCount"`
+	FilteredCount int            `json:"filteredCount"`
+	HasMore       bool           `json:"hasMore"`
+}
+
+// DepartmentPage is one page of departments
+type DepartmentPage struct {
+	Records       []*Department `json:"records"`
+	Bookmark      string        `json:"bookmark"`
+	RecordCount   int           `json:"recordCount"`
+	FilteredCount int           `json:"filteredCount"`
+	HasMore       bool          `json:"hasMore"`
+}
+
+// CoursePage is one page of course offerings
+type CoursePage struct {
+	Records       []*CourseOffering `json:"records"`
+	Bookmark      string            `json:"bookmark"`
+	RecordCount   int               `json:"recordCount"`
+	FilteredCount int               `json:"filteredCount"`
+	HasMore       bool              `json:"hasMore"`
+}
+
+// DocumentPage is one page of document uploads
+type DocumentPage struct {
+	Records       []*DocumentUpload `json:"records"`
+	Bookmark      string            `json:"bookmark"`
+	RecordCount   int               `json:"recordCount"`
+	FilteredCount int               `json:"filteredCount"`
+	HasMore       bool              `json:"hasMore"`
+}
+
+// RegistrationPage is one page of semester registrations
+type RegistrationPage struct {
+	Records       []*SemesterRegistration `json:"records"`
+	Bookmark      string                  `json:"bookmark"`
+	RecordCount   int                     `json:"recordCount"`
+	FilteredCount int                     `json:"filteredCount"`
+	HasMore       bool                    `json:"hasMore"`
+}
+
+// ConsentPage is one page of consent records
+type ConsentPage struct {
+	Records       []*ConsentRecord `json:"records"`
+	Bookmark      string           `json:"bookmark"`
+	RecordCount   int              `json:"recordCount"`
+	FilteredCount int              `json:"filteredCount"`
+	HasMore       bool             `json:"hasMore"`
+}
+
+// pageSource is one composite-key range read by pageIndexKeys. Ranges with a collection
+// are read from that private data collection.
+type pageSource struct {
+	objectType string
+	attributes []string
+	collection string
+}
+
+// pageIndexKeys returns up to pageSize index keys read from the sources in order, and the
+// bookmark of the next page ("" once every source is exhausted). The bookmark has the form
+// "{source}:{position}", where position is the state database bookmark for public ranges
+// and an offset for private ranges, which have no paginated query.
+func pageIndexKeys(ctx contractapi.TransactionContextInterface, sources []pageSource, bookmark string, pageSize int) ([]string, string, error) {
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	source, position := 0, ""
+	if bookmark != "" {
+		parts := strings.SplitN(bookmark, ":", 2)
+		if len(parts) != 2 {
+			return nil, "", fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+		if _, err := fmt.Sscanf(parts[0], "%d", &source); err != nil || source < 0 || source >= len(sources) {
+			return nil, "", fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+		position = parts[1]
+	}
+
+	keys := []string{}
+	for ; source < len(sources); source, position = source+1, "" {
+		remaining := pageSize - len(keys)
+		if remaining == 0 {
+			return keys, fmt.Sprintf("%d:", source), nil
+		}
+		src := sources[source]
+
+		if src.collection == "" {
+			iter, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(src.objectType, src.attributes, int32(remaining), position)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to query %s: %w", src.objectType, err)
+			}
+			fetched := 0
+			for iter.HasNext() {
+				kv, err := iter.Next()
+				if err != nil {
+					iter.Close()
+					return nil, "", fmt.Errorf("failed to iterate %s: %w", src.objectType, err)
+				}
+				keys = append(keys, kv.Key)
+				fetched++
+			}
+			iter.Close()
+			if fetched == remaining && metadata.Bookmark != "" {
+				return keys, fmt.Sprintf("%d:%s", source, metadata.Bookmark), nil
+			}
+			continue
+		}
+
+		offset := 0
+		if position != "" {
+			if _, err := fmt.Sscanf(position, "%d", &offset); err != nil || offset < 0 {
+				return nil, "", fmt.Errorf("invalid bookmark %q", bookmark)
+			}
+		}
+		iter, err := ctx.GetStub().GetPrivateDataByPartialCompositeKey(src.collection, src.objectType, src.attributes)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to query %s: %w", src.objectType, err)
+		}
+		for skipped, taken := 0, 0; iter.HasNext(); {
+			kv, err := iter.Next()
+			if err != nil {
+				iter.Close()
+				return nil, "", fmt.Errorf("failed to iterate %s: %w", src.objectType, err)
+			}
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			if taken == remaining {
+				iter.Close()
+				return keys, fmt.Sprintf("%d:%d", source, offset+taken), nil
+			}
+			keys = append(keys, kv.Key)
+			taken++
+		}
+		iter.Close()
+	}
+
+	return keys, "", nil
+}
+
+// QueryStudentsByDepartment returns students in a department with pagination
+func (s *SmartContract) QueryStudentsByDepartment(ctx contractapi.TransactionContextInterface, department string, bookmark string, pageSize int) (*StudentPage, error) {
+	// Validate page size
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50 // Default to 50 records per page
+	}
+
+	// Check department access
+	err := checkDepartmentAccess(ctx, department)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query using composite key: student~dept~{Department}~{RollNumber}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(StudentDeptKey, []string{department}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students by department: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	students := []*Student{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+		}
+
+		// Extract student roll number from composite key
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		rollNumber := compositeKeyParts[1] // student~dept~{Department}~{RollNumber}
+
+		// Get actual student record
+		studentBytes, err := getAssetState(ctx, DocTypeStudent, rollNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read student %s: %v", rollNumber, err)
+		}
+		if studentBytes == nil {
+			continue // Skip if student doesn't exist
+		}
+
+		var student Student
+		err = json.Unmarshal(studentBytes, &student)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
+		}
+
+		students = append(students, &student)
+	}
+
+	result := &StudentPage{
+		Records:       students,
+		Bookmark:      metadata.Bookmark,
+		RecordCount:   len(students),
+		FilteredCount: int(metadata.FetchedRecordsCount) - len(students),
+		HasMore:       metadata.Bookmark != "",
+	}
+
+	return result, nil
+}
+
+// QueryStudentsByYear returns students by enrollment year with pagination
+func (s *SmartContract) QueryStudentsByYear(ctx contractapi.TransactionContextInterface, year int, bookmark string, pageSize int) (*StudentPage, error) {
+	// Validate page size
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	// Validate year
+	if year < 1950 {
+		return nil, fmt.Errorf("invalid enrollment year: %d", year)
+	}
+
+	// Query using composite key: student~year~{EnrollmentYear}~{RollNumber}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(StudentYearKey, []string{fmt.Sprintf("%d", year)}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students by year: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	students := []*Student{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+		}
+
+		// Extract student roll number from composite key
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		rollNumber := compositeKeyParts[1] // student~year~{Year}~{RollNumber}
+
+		// Get actual student record
+		studentBytes, err := getAssetState(ctx, DocTypeStudent, rollNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read student %s: %v", rollNumber, err)
+		}
+		if studentBytes == nil {
+			continue
+		}
+
+		var student Student
+		err = json.Unmarshal(studentBytes, &student)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
+		}
+
+		// Skip students the caller is not allowed to read
+		if s.authorizeStudentRead(ctx, &student) != nil {
+			continue
+		}
+
+		students = append(students, &student)
+	}
+
+	result := &StudentPage{
+		Records:       students,
+		Bookmark:      metadata.Bookmark,
+		RecordCount:   len(students),
+		FilteredCount: int(metadata.FetchedRecordsCount) - len(students),
+		HasMore:       metadata.Bookmark != "",
+	}
+
+	return result, nil
+}
+
+// QueryStudentsByStatus returns students by status with pagination
+func (s *SmartContract) QueryStudentsByStatus(ctx contractapi.TransactionContextInterface, status string, bookmark string, pageSize int) (*StudentPage, error) {
+	// Validate page size
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	// Validate status
+	err := validateStatus(status)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query using composite key: student~status~{Status}~{RollNumber}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(StudentStatusKey, []string{status}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students by status: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	students := []*Student{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+		}
+
+		// Extract student roll number from composite key
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		rollNumber := compositeKeyParts[1] // student~status~{Status}~{RollNumber}
+
+		// Get actual student record
+		studentBytes, err := getAssetState(ctx, DocTypeStudent, rollNumber)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read student %s: %v", rollNumber, err)
+		}
+		if studentBytes == nil {
+			continue
+		}
+
+		var student Student
+		err = json.Unmarshal(studentBytes, &student)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
+		}
+
+		// Skip students the caller is not allowed to read
+		if s.authorizeStudentRead(ctx, &student) != nil {
+			continue
+		}
+
+		students = append(students, &student)
+	}
+
+	result := &StudentPage{
+		Records:       students,
+		Bookmark:      metadata.Bookmark,
+		RecordCount:   len(students),
+		FilteredCount: int(metadata.FetchedRecordsCount) - len(students),
+		HasMore:       metadata.Bookmark != "",
+	}
+
+	return result, nil
+}
+
+// QueryRecordsBySemester returns academic records by semester with pagination
+func (s *SmartContract) QueryRecordsBySemester(ctx contractapi.TransactionContextInterface, semester int, bookmark string, pageSize int) (*RecordPage, error) {
+	// Validate page size
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	// Validate semester
+	err := validateSemester(semester)
+	if err != nil {
+		return nil, err
+	}
+
+	// Query using composite key: record~semester~{Semester}~{StudentID}~{RecordID}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(RecordSemesterKey, []string{fmt.Sprintf("%d", semester)}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records by semester: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	records := []*AcademicRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+		}
+
+		// Extract record ID from composite key
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		recordID := compositeKeyParts[2] // record~semester~{Semester}~{StudentID}~{RecordID}
+
+		// Get actual record
+		recordBytes, err := getAssetState(ctx, DocTypeRecord, recordID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record %s: %v", recordID, err)
+		}
+		if recordBytes == nil {
+			continue
+		}
+
+		var record AcademicRecord
+		err = json.Unmarshal(recordBytes, &record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record: %v", err)
+		}
+
+		// Check read access
+		err = s.authorizeRecordRead(ctx, &record)
+		if err != nil {
+			continue // Skip records the caller may not read
+		}
+
+		view, err := s.viewAcademicRecord(ctx, &record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, view)
+	}
+
+	result := &RecordPage{
+		Records:       records,
+		Bookmark:      metadata.Bookmark,
+		RecordCount:   len(records),
+		FilteredCount: int(metadata.FetchedRecordsCount) - len(records),
+		HasMore:       metadata.Bookmark != "",
+	}
+
+	return result, nil
+}
+
+// QueryRecordsByStatus returns academic records by status with pagination
+func (s *SmartContract) QueryRecordsByStatus(ctx contractapi.TransactionContextInterface, status string, bookmark string, pageSize int) (*RecordPage, error) {
+	// Validate page size
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+
+	// Validate status
+	validStatuses := []string{StatusDraft, RecordSubmitted, RecordApproved}
+	isValid := false
+	for _, validStatus := range validStatuses {
+		if status == validStatus {
+			isValid = true
+			break
+		}
+	}
+	if !isValid {
+		return nil, fmt.Errorf("invalid record status: %s (must be DRAFT, SUBMITTED, or APPROVED)", status)
+	}
+
+	// Query using composite key: record~status~{Status}~{StudentID}~{RecordID}
+	resultsIterator, metadata, err := ctx.GetStub().GetStateByPartialCompositeKeyWithPagination(RecordStatusKey, []string{status}, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records by status: %w", err)
+	}
+	defer resultsIterator.Close()
+
+	records := []*AcademicRecord{}
+	for resultsIterator.HasNext() {
+		queryResponse, err := resultsIterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate query results: %v", err)
+		}
+
+		// Extract record ID from composite key
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(queryResponse.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %v", err)
+		}
+		recordID := compositeKeyParts[2] // record~status~{Status}~{StudentID}~{RecordID}
+
+		// Get actual record
+		recordBytes, err := getAssetState(ctx, DocTypeRecord, recordID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read record %s: %v", recordID, err)
+		}
+		if recordBytes == nil {
+			continue
+		}
+
+		var record AcademicRecord
+		err = json.Unmarshal(recordBytes, &record)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record: %v", err)
+		}
+
+		// Check read access
+		err = s.authorizeRecordRead(ctx, &record)
+		if err != nil {
+			continue // Skip records the caller may not read
+		}
+
+		view, err := s.viewAcademicRecord(ctx, &record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, view)
+	}
+
+	result := &RecordPage{
+		Records:       records,
+		Bookmark:      metadata.Bookmark,
+		RecordCount:   len(records),
+		FilteredCount: int(metadata.FetchedRecordsCount) - len(records),
+		HasMore:       metadata.Bookmark != "",
+	}
+
+	return result, nil
+}
+
+// QueryPendingRecords returns a page of records awaiting approval (DRAFT + SUBMITTED)
+func (s *SmartContract) QueryPendingRecords(ctx contractapi.TransactionContextInterface, bookmark string, pageSize int) (*RecordPage, error) {
+	// DRAFT records are paged first, then SUBMITTED; the bookmark records which status
+	// range the next page resumes in.
+	sources := []pageSource{
+		{objectType: RecordStatusKey, attributes: []string{StatusDraft}},
+		{objectType: RecordStatusKey, attributes: []string{RecordSubmitted}},
+	}
+	keys, nextBookmark, err := pageIndexKeys(ctx, sources, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending records: %w", err)
+	}
+
+	allRecords := []*AcademicRecord{}
+	for _, key := range keys {
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(compositeKeyParts) < 3 {
+			continue
+		}
+		recordID := compositeKeyParts[2]
+
+		recordBytes, err := getAssetState(ctx, DocTypeRecord, recordID)
+		if err != nil || recordBytes == nil {
+			continue
+		}
+
+		var record AcademicRecord
+		err = json.Unmarshal(recordBytes, &record)
+		if err != nil {
+			continue
+		}
+
+		// Check read access
+		err = s.authorizeRecordRead(ctx, &record)
+		if err != nil {
+			continue
+		}
+
+		view, err := s.viewAcademicRecord(ctx, &record)
+		if err != nil {
+			continue
+		}
+		allRecords = append(allRecords, view)
+	}
+
+	result := &RecordPage{
+		Records:       allRecords,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(allRecords),
+		FilteredCount: len(keys) - len(allRecords),
+		HasMore:       nextBookmark != "",
+	}
+
+	return result, nil
+}
+
+// QueryRecordsByDepartment returns a page of a department's academic records. Semester,
+// academicYear and status are optional filters (0 or "" matches any); records read for the
+// page that do not match are reported in FilteredCount. With a year, the page is read from the
+// department's records of that year only. Records created before the academic year was stored
+// have an empty academicYear, so a year filter never matches them; the year was not kept
+// anywhere on the ledger and cannot be backfilled.
+func (s *SmartContract) QueryRecordsByDepartment(ctx contractapi.TransactionContextInterface,
+	department string, semester int, academicYear, status, bookmark string, pageSize int) (*RecordPage, error) {
+
+	department = strings.ToUpper(department)
+	if err := checkDepartmentAccess(ctx, department); err != nil {
+		return nil, err
+	}
+
+	if semester != 0 {
+		if err := validateSemester(semester); err != nil {
+			return nil, err
+		}
+	}
+	academicYear = strings.TrimSpace(academicYear)
+	if academicYear != "" {
+		if err := validateAcademicYear(academicYear); err != nil {
+			return nil, err
+		}
+	}
+	status = strings.ToUpper(strings.TrimSpace(status))
+	if status != "" {
+		validStatuses := []string{RecordDraft, RecordSubmitted, RecordApproved, RecordFacultyApproved, RecordHODApproved,
+			RecordESLocked, RecordDeanApproved, RecordFinalized, RecordRejected, RecordSuperseded}
+		isValid := false
+		for _, validStatus := range validStatuses {
+			if status == validStatus {
+				isValid = true
+				break
+			}
+		}
+		if !isValid {
+			return nil, fmt.Errorf("invalid record status: %s", status)
+		}
+	}
+
+	// Page through record~dept~year~{Department}~{AcademicYear}~{StudentID}~{RecordID} for a
+	// year, otherwise record~department~{Department}~{StudentID}~{RecordID}
+	source := pageSource{objectType: RecordDeptKey, attributes: []string{department}}
+	if academicYear != "" {
+		source = pageSource{objectType: RecordDeptYearKey, attributes: []string{department, academicYear}}
+	}
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{source}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query records by department: %w", err)
+	}
+
+	records := []*AcademicRecord{}
+	for _, key := range keys {
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(compositeKeyParts) < 3 {
+			continue
+		}
+		recordID := compositeKeyParts[len(compositeKeyParts)-1]
+
+		// Filter on the public stub and only resolve the grades of records that match
+		record, err := s.readRecordState(ctx, recordID)
+		if err != nil {
+			continue
+		}
+		if semester != 0 && record.Semester != semester {
+			continue
+		}
+		if academicYear != "" && record.AcademicYear != academicYear {
+			continue
+		}
+		if status != "" && record.Status != status {
+			continue
+		}
+
+		view, err := s.viewAcademicRecord(ctx, record)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, view)
+	}
+
+	return &RecordPage{
+		Records:       records,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(records),
+		FilteredCount: len(keys) - len(records),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// ==================== Department Management ====================
+
+// CreateDepartment creates a new department
+func (s *SmartContract) CreateDepartment(ctx contractapi.TransactionContextInterface,
+	departmentID, departmentName, hod, email, phone string) error {
+
+	// Normalize department ID to uppercase
+	departmentID = strings.ToUpper(departmentID)
+
+	// Check if department already exists
+	exists, err := s.departmentExists(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("department %s already exists", departmentID)
+	}
+
+	// Records of the department keep their grades in its collection until release
+	if err := checkGradesCollection(ctx, departmentID); err != nil {
+		return err
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client ID: %v", err)
+	}
+
+	department := Department{
+		DocType:        DocTypeDepartment,
+		DepartmentID:   departmentID,
+		DepartmentName: departmentName,
+		HOD:            hod,
+		Email:          email,
+		Phone:          phone,
+		CreatedBy:      clientID,
+		CreatedAt:      time.Now(),
+		ModifiedBy:     clientID,
+		ModifiedAt:     time.Now(),
+	}
+
+	departmentJSON, err := json.Marshal(department)
+	if err != nil {
+		return fmt.Errorf("failed to marshal department: %v", err)
+	}
+
+	err = putAssetState(ctx, DocTypeDepartment, departmentID, departmentJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put department state: %v", err)
+	}
+
+	// Create composite key for querying all departments
+	deptIndexKey, err := ctx.GetStub().CreateCompositeKey(DepartmentAllKey, []string{departmentID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(deptIndexKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put department index: %v", err)
+	}
+
+	return nil
+}
+
+// GetDepartment retrieves a department by ID
+func (s *SmartContract) GetDepartment(ctx contractapi.TransactionContextInterface, departmentID string) (*Department, error) {
+	// Normalize department ID to uppercase
+	departmentID = strings.ToUpper(departmentID)
+
+	departmentJSON, err := getAssetState(ctx, DocTypeDepartment, departmentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read department: %v", err)
+	}
+	if departmentJSON == nil {
+		return nil, fmt.Errorf("department %s does not exist", departmentID)
+	}
+
+	var department Department
+	err = json.Unmarshal(departmentJSON, &department)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal department: %v", err)
+	}
+
+	return &department, nil
+}
+
+// GetAllDepartments retrieves a page of departments
+func (s *SmartContract) GetAllDepartments(ctx contractapi.TransactionContextInterface, bookmark string, pageSize int) (*DepartmentPage, error) {
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: DepartmentAllKey, attributes: []string{}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get departments: %v", err)
+	}
+
+	departments := []*Department{}
+	for _, key := range keys {
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(compositeKeyParts) < 1 {
+			continue
+		}
+
+		departmentID := compositeKeyParts[0]
+		departmentJSON, err := getAssetState(ctx, DocTypeDepartment, departmentID)
+		if err != nil || departmentJSON == nil {
+			continue
+		}
+
+		var department Department
+		err = json.Unmarshal(departmentJSON, &department)
+		if err != nil {
+			continue
+		}
+
+		departments = append(departments, &department)
+	}
+
+	return &DepartmentPage{
+		Records:       departments,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(departments),
+		FilteredCount: len(keys) - len(departments),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// UpdateDepartment updates department information
+func (s *SmartContract) UpdateDepartment(ctx contractapi.TransactionContextInterface,
+	departmentID string, updateData string) error {
+
+	department, err := s.GetDepartment(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+
+	var updates map[string]interface{}
+	err = json.Unmarshal([]byte(updateData), &updates)
+	if err != nil {
+		return fmt.Errorf("failed to parse update data: %v", err)
+	}
+
+	// Apply updates
+	if hod, ok := updates["hod"].(string); ok {
+		department.HOD = hod
+	}
+	if email, ok := updates["email"].(string); ok {
+		department.Email = email
+	}
+	if phone, ok := updates["phone"].(string); ok {
+		department.Phone = phone
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client ID: %v", err)
+	}
+
+	department.ModifiedBy = clientID
+	department.ModifiedAt = time.Now()
+
+	departmentJSON, err := json.Marshal(department)
+	if err != nil {
+		return fmt.Errorf("failed to marshal department: %v", err)
+	}
+
+	return putAssetState(ctx, DocTypeDepartment, departmentID, departmentJSON)
+}
+
+// departmentExists checks if a department exists
+func (s *SmartContract) departmentExists(ctx contractapi.TransactionContextInterface, departmentID string) (bool, error) {
+	// Normalize department ID to uppercase
+	departmentID = strings.ToUpper(departmentID)
+
+	departmentJSON, err := getAssetState(ctx, DocTypeDepartment, departmentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read department: %v", err)
+	}
+	return departmentJSON != nil, nil
+}
+
+// ==================== Course Offering Management ====================
+
+// CreateCourseOffering creates a new course offering (many-to-many relationship)
+func (s *SmartContract) CreateCourseOffering(ctx contractapi.TransactionContextInterface,
+	departmentID, courseCode, courseName string, credits float64, semester int, academicYear string) error {
+
+	// Normalize department ID to uppercase
+	departmentID = strings.ToUpper(departmentID)
+
+	// Department users can only offer courses in their own department
+	if err := checkDepartmentAccess(ctx, departmentID); err != nil {
+		return err
+	}
+
+	// Verify department exists
+	exists, err := s.departmentExists(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("department %s does not exist", departmentID)
+	}
+
+	// Validate credits
+	if err := validateCredits(credits); err != nil {
+		return err
+	}
+
+	// Validate semester
+	if err := validateSemester(semester); err != nil {
+		return err
+	}
+
+	// Create unique offering ID
+	offeringID := fmt.Sprintf("%s-%s-%d-%s", departmentID, courseCode, semester, academicYear)
+
+	// Check if offering already exists
+	offeringJSON, err := getAssetState(ctx, DocTypeCourseOffering, offeringID)
+	if err != nil {
+		return fmt.Errorf("failed to read offering: %v", err)
+	}
+	if offeringJSON != nil {
+		return fmt.Errorf("course offering %s already exists", offeringID)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client ID: %v", err)
+	}
+
+	offering := CourseOffering{
+		DocType:      DocTypeCourseOffering,
+		OfferingID:   offeringID,
+		DepartmentID: departmentID,
+		CourseCode:   courseCode,
+		CourseName:   courseName,
+		Credits:      credits,
+		Semester:     semester,
+		AcademicYear: academicYear,
+		IsActive:     true,
+		CreatedBy:    clientID,
+		CreatedAt:    time.Now(),
+		ModifiedBy:   clientID,
+		ModifiedAt:   time.Now(),
+	}
+
+	offeringJSON, err = json.Marshal(offering)
+	if err != nil {
+		return fmt.Errorf("failed to marshal offering: %v", err)
+	}
+
+	err = putAssetState(ctx, DocTypeCourseOffering, offeringID, offeringJSON)
+	if err != nil {
+		return fmt.Errorf("failed to put offering state: %v", err)
+	}
+
+	// Create composite key for querying by department
+	deptCourseKey, err := ctx.GetStub().CreateCompositeKey(CourseDeptKey, []string{departmentID, offeringID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %v", err)
+	}
+
+	err = ctx.GetStub().PutState(deptCourseKey, []byte{0x00})
+	if err != nil {
+		return fmt.Errorf("failed to put course-dept index: %v", err)
+	}
+
+	return nil
+}
+
+// GetCourseOffering retrieves a course offering by ID
+func (s *SmartContract) GetCourseOffering(ctx contractapi.TransactionContextInterface, offeringID string) (*CourseOffering, error) {
+	offeringJSON, err := getAssetState(ctx, DocTypeCourseOffering, offeringID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read course offering: %v", err)
+	}
+	if offeringJSON == nil {
+		return nil, fmt.Errorf("course offering %s does not exist", offeringID)
+	}
+
+	var offering CourseOffering
+	err = json.Unmarshal(offeringJSON, &offering)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal course offering: %v", err)
+	}
+
+	return &offering, nil
+}
+
+// GetCoursesByDepartment retrieves a page of courses offered by a department
+func (s *SmartContract) GetCoursesByDepartment(ctx contractapi.TransactionContextInterface, departmentID string, bookmark string, pageSize int) (*CoursePage, error) {
+	// Normalize department ID to uppercase
+	departmentID = strings.ToUpper(departmentID)
+
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: CourseDeptKey, attributes: []string{departmentID}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get courses: %v", err)
+	}
+
+	courses := []*CourseOffering{}
+	for _, key := range keys {
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(compositeKeyParts) < 2 {
+			continue
+		}
+
+		offeringID := compositeKeyParts[1]
+		offeringJSON, err := getAssetState(ctx, DocTypeCourseOffering, offeringID)
+		if err != nil || offeringJSON == nil {
+			continue
+		}
+
+		var offering CourseOffering
+		err = json.Unmarshal(offeringJSON, &offering)
+		if err != nil {
+			continue
+		}
+
+		courses = append(courses, &offering)
+	}
+
+	return &CoursePage{
+		Records:       courses,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(courses),
+		FilteredCount: len(keys) - len(courses),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// UpdateCourseOffering updates course offering details
+func (s *SmartContract) UpdateCourseOffering(ctx contractapi.TransactionContextInterface,
+	offeringID string, isActive bool) error {
+
+	offering, err := s.GetCourseOffering(ctx, offeringID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDepartmentAccess(ctx, offering.DepartmentID); err != nil {
+		return err
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client ID: %v", err)
+	}
+
+	offering.IsActive = isActive
+	offering.ModifiedBy = clientID
+	offering.ModifiedAt = time.Now()
+
+	offeringJSON, err := json.Marshal(offering)
+	if err != nil {
+		return fmt.Errorf("failed to marshal offering: %v", err)
+	}
+
+	return putAssetState(ctx, DocTypeCourseOffering, offeringID, offeringJSON)
+}
+
+// GetStudentsByDepartment retrieves students by department (replaces GetStudentsByFaculty)
+func (s *SmartContract) GetStudentsByDepartment(ctx contractapi.TransactionContextInterface, department string, bookmark string, pageSize int) (*StudentPage, error) {
+	// Normalize department to uppercase for case-insensitive matching
+	department = strings.ToUpper(department)
+
+	// Department can view their own students, admin can view any department
+	err := checkDepartmentAccess(ctx, department)
+	if err != nil {
+		return nil, err
+	}
+
+	// Use composite key instead of CouchDB query for LevelDB compatibility
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: StudentDeptKey, attributes: []string{department}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students: %v", err)
+	}
+
+	students := []*Student{}
+	for _, key := range keys {
+		// Extract roll number from composite key
+		_, compositeKeyParts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil {
+			continue
+		}
+
+		if len(compositeKeyParts) < 2 {
+			continue
+		}
+
+		rollNumber := compositeKeyParts[1] // student~dept~{Department}~{RollNumber}
+
+		// Get student record
+		studentJSON, err := getAssetState(ctx, DocTypeStudent, rollNumber)
+		if err != nil || studentJSON == nil {
+			continue
+		}
+
+		var student Student
+		err = json.Unmarshal(studentJSON, &student)
+		if err != nil {
+			continue
+		}
+
+		students = append(students, &student)
+	}
+
+	return &StudentPage{
+		Records:       students,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(students),
+		FilteredCount: len(keys) - len(students),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// ============================================================
+// MULTI-PARTY APPROVAL WORKFLOW — New Types & Constants
+// ============================================================
+
+// Extended approval status constants (added to existing DRAFT/SUBMITTED/APPROVED)
+const (
+	RecordFacultyApproved  = "FACULTY_APPROVED"
+	RecordHODApproved      = "HOD_APPROVED"
+	RecordESLocked         = "EXAM_LOCKED"
+	RecordDeanApproved     = "DEAN_APPROVED"
+	RecordFinalized        = "FINALIZED"
+	RecordRejected         = "REJECTED"
+	RecordSuperseded       = "SUPERSEDED"
 
 	// Role identifiers used in approval chain
 	RoleFaculty      = "faculty"
@@ -2636,1118 +4719,4234 @@ const (
 	RoleExamSection  = "exam_section"
 	RoleDeanAcademic = "dean_academic"
 	RoleAdmin        = "admin"
+	RoleStudent      = "student"
+	RoleDepartment   = "department"
+
+	// Composite key prefixes for new entities
+	ApprovalKey            = "approval~record"
+	DocumentKey            = "document~student"
+	DocumentHashKey        = "document~hash"
+	DocumentHashHistoryKey = "document~hashhistory"
+	SemesterRegKey         = "semreg~student"
+
+	// Document lifecycle statuses
+	DocStatusCurrent    = "CURRENT"
+	DocStatusSuperseded = "SUPERSEDED"
+	DocStatusRetracted  = "RETRACTED"
+)
+
+// ApprovalStep represents a single approval in the multi-party chain
+type ApprovalStep struct {
+	Role       string    `json:"role"`
+	ApprovedBy string    `json:"approvedBy"`
+	Timestamp  time.Time `json:"timestamp"`
+	Comment    string    `json:"comment"`
+	TxID       string    `json:"txId"`
+}
+
+// ApprovalRecord holds full approval chain for an academic record
+type ApprovalRecord struct {
+	RecordID      string          `json:"recordId"`
+	StudentID     string          `json:"studentId"`
+	Department    string          `json:"department"`
+	Semester      int             `json:"semester"`
+	CurrentStatus string          `json:"currentStatus"`
+	ApprovalChain []ApprovalStep  `json:"approvalChain"`
+	Rejections    []ApprovalStep  `json:"rejections"`
+	CreatedAt     time.Time       `json:"createdAt"`
+	UpdatedAt     time.Time       `json:"updatedAt"`
+}
+
+// DocumentUpload represents a document uploaded and hashed on the blockchain
+type DocumentUpload struct {
+	DocType      string    `json:"docType"`
+	DocID        string    `json:"docId"`
+	StudentID    string    `json:"studentId"`
+	DocumentType string    `json:"documentType"` // GRADE_SHEET, DEGREE_CERT, TRANSCRIPT, AADHAAR, PHOTO, OTHER
+	SHA256Hash   string    `json:"sha256Hash"`
+	FileName     string    `json:"fileName"`
+	Semester     int       `json:"semester"`   // 0 = not semester-specific
+	AcademicYear string    `json:"academicYear"`
+	UploadedBy   string    `json:"uploadedBy"`
+	UploadedAt   time.Time `json:"uploadedAt"`
+	IsVerified   bool      `json:"isVerified"`
+	VerifiedBy   string    `json:"verifiedBy"`
+	VerifiedAt   time.Time `json:"verifiedAt,omitempty"`
+
+	Status           string    `json:"status"`                 // CURRENT, SUPERSEDED, RETRACTED
+	Supersedes       string    `json:"supersedes,omitempty"`   // DocID of the version this one replaced
+	SupersededBy     string    `json:"supersededBy,omitempty"` // DocID of the version that replaced this one
+	RetractedBy      string    `json:"retractedBy,omitempty"`
+	RetractedAt      time.Time `json:"retractedAt,omitempty"`
+	RetractionReason string    `json:"retractionReason,omitempty"`
+
+	// Review pipeline stage set by UpdateDocumentStatus; empty means UPLOADED
+	DocumentStatus  string    `json:"documentStatus,omitempty"`
+	StatusUpdatedBy string    `json:"statusUpdatedBy,omitempty"`
+	StatusUpdatedAt time.Time `json:"statusUpdatedAt,omitempty"`
+}
+
+// SemesterRegistration represents a student's semester registration
+type SemesterRegistration struct {
+	DocType        string    `json:"docType"`
+	RegID          string    `json:"regId"`
+	StudentID      string    `json:"studentId"`
+	Semester       int       `json:"semester"`
+	AcademicYear   string    `json:"academicYear"`
+	FacultyAdvisor string    `json:"facultyAdvisor"`
+	Status         string    `json:"status"` // REGISTERED, COMPLETED, DROPPED
+	RegisteredBy   string    `json:"registeredBy"`
+	RegisteredAt   time.Time `json:"registeredAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ============================================================
+// HELPER: getOrCreateApprovalRecord
+// ============================================================
+
+func (s *SmartContract) getOrCreateApprovalRecord(ctx contractapi.TransactionContextInterface, recordID string) (*ApprovalRecord, error) {
+	approvalKey, err := ctx.GetStub().CreateCompositeKey(ApprovalKey, []string{recordID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create approval key: %w", err)
+	}
+
+	approvalJSON, err := ctx.GetStub().GetState(approvalKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approval record: %w", err)
+	}
+
+	if approvalJSON != nil {
+		var ar ApprovalRecord
+		if err := json.Unmarshal(approvalJSON, &ar); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal approval record: %w", err)
+		}
+		return &ar, nil
+	}
+
+	// Fetch the academic record for context; the public state is enough here
+	rec, err := s.readRecordState(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	ar := ApprovalRecord{
+		RecordID:      recordID,
+		StudentID:     rec.StudentID,
+		Department:    rec.Department,
+		Semester:      rec.Semester,
+		CurrentStatus: rec.Status,
+		ApprovalChain: []ApprovalStep{},
+		Rejections:    []ApprovalStep{},
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	return &ar, nil
+}
+
+func (s *SmartContract) saveApprovalRecord(ctx contractapi.TransactionContextInterface, ar *ApprovalRecord) error {
+	approvalKey, err := ctx.GetStub().CreateCompositeKey(ApprovalKey, []string{ar.RecordID})
+	if err != nil {
+		return fmt.Errorf("failed to create approval key: %w", err)
+	}
+	arJSON, err := json.Marshal(ar)
+	if err != nil {
+		return fmt.Errorf("failed to marshal approval record: %w", err)
+	}
+	return ctx.GetStub().PutState(approvalKey, arJSON)
+}
+
+// updateRecordStatus updates the AcademicRecord's status field and composite keys
+func (s *SmartContract) updateRecordStatus(ctx contractapi.TransactionContextInterface, recordID, newStatus string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+
+	oldStatus := rec.Status
+
+	// Update composite status key
+	oldStatusKey, _ := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{oldStatus, rec.StudentID, recordID})
+	ctx.GetStub().DelState(oldStatusKey)
+
+	newStatusKey, _ := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{newStatus, rec.StudentID, recordID})
+	ctx.GetStub().PutState(newStatusKey, []byte{0x00})
+
+	rec.Status = newStatus
+	return s.putAcademicRecord(ctx, rec)
+}
+
+// ============================================================
+// APPROVAL WORKFLOW FUNCTIONS
+// ============================================================
+
+// GetApprovalStatus retrieves the full approval chain for a record
+func (s *SmartContract) GetApprovalStatus(ctx contractapi.TransactionContextInterface, recordID string) (*ApprovalRecord, error) {
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeRead(ctx, ar.StudentID, ar.Department); err != nil {
+		return nil, err
+	}
+	return ar, nil
+}
+
+// SubmitForApproval moves a DRAFT record to SUBMITTED status (department submits)
+func (s *SmartContract) SubmitForApproval(ctx contractapi.TransactionContextInterface, recordID string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartmentAccess(ctx, rec.Department); err != nil {
+		return err
+	}
+
+	if rec.Status != RecordDraft {
+		return fmt.Errorf("only DRAFT records can be submitted for approval, current status: %s", rec.Status)
+	}
+
+	student, err := s.readStudent(ctx, rec.StudentID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkStudentStatusFor(ctx, "SubmitForApproval", student, rec.Semester); err != nil {
+		return err
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	// Update record status
+	if err := s.updateRecordStatus(ctx, recordID, RecordSubmitted); err != nil {
+		return fmt.Errorf("failed to update record status: %w", err)
+	}
+
+	// Initialize approval record
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordSubmitted
+	ar.UpdatedAt = now
+
+	step := ApprovalStep{
+		Role:       RoleDepartment,
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    "Submitted for approval",
+		TxID:       ctx.GetStub().GetTxID(),
+	}
+	ar.ApprovalChain = append(ar.ApprovalChain, step)
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"recordId":    recordID,
+		"status":      RecordSubmitted,
+		"submittedBy": clientID,
+		"timestamp":   now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordSubmittedForApproval", eventJSON)
+
+	return nil
+}
+
+// FacultyApprove records the faculty's approval of an academic record
+func (s *SmartContract) FacultyApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartmentAccess(ctx, rec.Department); err != nil {
+		return err
+	}
+
+	if rec.Status != RecordSubmitted {
+		return fmt.Errorf("record must be in SUBMITTED status for faculty approval, current: %s", rec.Status)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	if err := s.updateRecordStatus(ctx, recordID, RecordFacultyApproved); err != nil {
+		return err
+	}
+
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordFacultyApproved
+	ar.UpdatedAt = now
+	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
+		Role:       RoleFaculty,
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    comment,
+		TxID:       ctx.GetStub().GetTxID(),
+	})
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{"recordId": recordID, "role": RoleFaculty, "approvedBy": clientID, "timestamp": now}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordFacultyApproved", eventJSON)
+
+	return nil
+}
+
+// HODApprove records the HOD's approval
+func (s *SmartContract) HODApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartmentAccess(ctx, rec.Department); err != nil {
+		return err
+	}
+
+	if rec.Status != RecordFacultyApproved {
+		return fmt.Errorf("record must be FACULTY_APPROVED before HOD approval, current: %s", rec.Status)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	if err := s.updateRecordStatus(ctx, recordID, RecordHODApproved); err != nil {
+		return err
+	}
+
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordHODApproved
+	ar.UpdatedAt = now
+	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
+		Role:       RoleHOD,
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    comment,
+		TxID:       ctx.GetStub().GetTxID(),
+	})
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{"recordId": recordID, "role": RoleHOD, "approvedBy": clientID}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordHODApproved", eventJSON)
+
+	return nil
+}
+
+// DACApprove is the final approval step — validates compliance, signs off, and finalizes record
+func (s *SmartContract) DACApprove(ctx contractapi.TransactionContextInterface, recordID, memberRole, comment string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+
+	if err := checkDepartmentAccess(ctx, rec.Department); err != nil {
+		return err
+	}
+
+	if rec.Status != RecordDeanApproved {
+		return fmt.Errorf("record must be DEAN_APPROVED before DAC finalization, current: %s", rec.Status)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	// Recompute the CGPA since this is the final step; this sets the record's CGPA and
+	// updates the records of later semesters
+	newCGPA, totalCredits, err := s.releaseRecordGPA(ctx, rec)
+	if err != nil {
+		return fmt.Errorf("failed to calculate CGPA: %w", err)
+	}
+
+	// Update student overall profile
+	student, err := s.readStudent(ctx, rec.StudentID)
+	if err == nil {
+		student.CurrentCGPA = newCGPA
+		student.TotalCreditsEarned = totalCredits
+		studentJSON, _ := json.Marshal(student)
+		putAssetState(ctx, DocTypeStudent, student.RollNumber, studentJSON)
+	}
+
+	if err := s.updateRecordStatus(ctx, recordID, RecordFinalized); err != nil {
+		return err
+	}
+
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordFinalized
+	ar.UpdatedAt = now
+	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
+		Role:       RoleDAC,
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    comment,
+		TxID:       ctx.GetStub().GetTxID(),
+	})
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	// Make the record FINALIZED / APPROVED structurally
+	rec.Status = RecordFinalized
+	rec.ApprovedBy = clientID
+	rec.Timestamp = now
+	if err := s.putAcademicRecord(ctx, rec); err != nil {
+		return err
+	}
+
+	// Emit Finalized event
+	eventPayload := map[string]interface{}{
+		"recordId":    recordID,
+		"studentId":   rec.StudentID,
+		"semester":    rec.Semester,
+		"sgpa":        rec.SGPA,
+		"cgpa":        rec.CGPA,
+		"studentCGPA": newCGPA,
+		"approvedBy":  clientID,
+		"timestamp":   now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordFinalized", eventJSON)
+
+	return nil
+}
+
+// ExamSectionApprove records Exam Section approval
+func (s *SmartContract) ExamSectionApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+
+	if rec.Status != RecordHODApproved {
+		return fmt.Errorf("record must be HOD_APPROVED before Exam Section locking, current: %s", rec.Status)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	if err := s.updateRecordStatus(ctx, recordID, RecordESLocked); err != nil {
+		return err
+	}
+
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordESLocked
+	ar.UpdatedAt = now
+	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
+		Role:       RoleExamSection,
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    comment,
+		TxID:       ctx.GetStub().GetTxID(),
+	})
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{"recordId": recordID, "role": RoleExamSection, "approvedBy": clientID}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordExamLocked", eventJSON)
+
+	return nil
+}
+
+// DeanAcademicApprove records Dean approval, but no longer finalizes the record.
+func (s *SmartContract) DeanAcademicApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+
+	if rec.Status != RecordESLocked {
+		return fmt.Errorf("record must be EXAM_LOCKED before Dean Academic approval, current: %s", rec.Status)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	if err := s.updateRecordStatus(ctx, recordID, RecordDeanApproved); err != nil {
+		return err
+	}
+
+	// Record dean approval
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordDeanApproved
+	ar.UpdatedAt = now
+	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
+		Role:       RoleDeanAcademic,
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    comment,
+		TxID:       ctx.GetStub().GetTxID(),
+	})
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"recordId":   recordID,
+		"role":       RoleDeanAcademic,
+		"approvedBy": clientID,
+		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordDeanApproved", eventJSON)
+
+	return nil
+}
+
+// RejectRecord allows any approver to reject and send back with a reason
+func (s *SmartContract) RejectRecord(ctx contractapi.TransactionContextInterface, recordID, reason string) error {
+	rec, err := s.readAcademicRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartmentAccess(ctx, rec.Department); err != nil {
+		return err
+	}
+
+	// Cannot reject if already released, rejected or superseded. Released records count toward
+	// the CGPA and are corrected with SupersedeAcademicRecord, which recomputes it.
+	if isRecordReleased(rec.Status) || rec.Status == RecordRejected || rec.Status == RecordSuperseded {
+		return fmt.Errorf("cannot reject record with status %s", rec.Status)
+	}
+
+	if reason == "" {
+		return fmt.Errorf("rejection reason is required")
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	// Reset back to DRAFT for correction
+	if err := s.updateRecordStatus(ctx, recordID, RecordDraft); err != nil {
+		return err
+	}
+
+	// Update rejection note on record
+	rec.Status = RecordDraft
+	rec.RejectionNote = reason
+	if err := s.putAcademicRecord(ctx, rec); err != nil {
+		return err
+	}
+
+	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	ar.CurrentStatus = RecordDraft
+	ar.UpdatedAt = now
+	ar.Rejections = append(ar.Rejections, ApprovalStep{
+		Role:       "any",
+		ApprovedBy: clientID,
+		Timestamp:  now,
+		Comment:    reason,
+		TxID:       ctx.GetStub().GetTxID(),
+	})
+	// Reset approval chain on rejection
+	ar.ApprovalChain = []ApprovalStep{}
+
+	if err := s.saveApprovalRecord(ctx, ar); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{"recordId": recordID, "rejectedBy": clientID, "reason": reason}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("RecordRejected", eventJSON)
+
+	return nil
+}
+
+// ============================================================
+// DOCUMENT UPLOAD & HASH VERIFICATION
+// ============================================================
+
+// isSensitiveDocType reports whether a document type's metadata must be kept out of public state
+func isSensitiveDocType(docType string) bool {
+	return docType == "AADHAAR" || docType == "PHOTO"
+}
+
+// getDocumentState, putDocumentState and delDocumentState route document keys to public
+// world state or, for sensitive document types, to the student private data collection
+func getDocumentState(ctx contractapi.TransactionContextInterface, sensitive bool, key string) ([]byte, error) {
+	if sensitive {
+		return ctx.GetStub().GetPrivateData(studentPrivateCollection, key)
+	}
+	return ctx.GetStub().GetState(key)
+}
+
+func putDocumentState(ctx contractapi.TransactionContextInterface, sensitive bool, key string, value []byte) error {
+	if sensitive {
+		return ctx.GetStub().PutPrivateData(studentPrivateCollection, key, value)
+	}
+	return ctx.GetStub().PutState(key, value)
+}
+
+func delDocumentState(ctx contractapi.TransactionContextInterface, sensitive bool, key string) error {
+	if sensitive {
+		return ctx.GetStub().DelPrivateData(studentPrivateCollection, key)
+	}
+	return ctx.GetStub().DelState(key)
+}
+
+func getDocumentStateByPartialKey(ctx contractapi.TransactionContextInterface, sensitive bool, objectType string, attributes []string) (shim.StateQueryIteratorInterface, error) {
+	if sensitive {
+		return ctx.GetStub().GetPrivateDataByPartialCompositeKey(studentPrivateCollection, objectType, attributes)
+	}
+	return ctx.GetStub().GetStateByPartialCompositeKey(objectType, attributes)
+}
+
+// currentDocumentForHash returns the docID registered as current for a hash, or "" if none is.
+// Entries written before index values became markers are keyed by the hash alone and hold the docID.
+func currentDocumentForHash(ctx contractapi.TransactionContextInterface, sensitive bool, sha256Hash string) (string, error) {
+	iter, err := getDocumentStateByPartialKey(ctx, sensitive, DocumentHashKey, []string{sha256Hash})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up hash: %w", err)
+	}
+	defer iter.Close()
+
+	if !iter.HasNext() {
+		return "", nil
+	}
+	kv, err := iter.Next()
+	if err != nil {
+		return "", fmt.Errorf("failed to look up hash: %w", err)
+	}
+	_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+	if err != nil {
+		return "", fmt.Errorf("failed to split hash key: %w", err)
+	}
+	if len(parts) > 1 {
+		return parts[1], nil
+	}
+	return string(kv.Value), nil
+}
+
+// documentFileArgs resolves the hash and file name of a document. Sensitive documents must pass
+// them as transient data ("sha256Hash", "fileName") so they never appear in the public transaction.
+func documentFileArgs(ctx contractapi.TransactionContextInterface, docType, sha256Hash, fileName string) (string, string, error) {
+	if !isSensitiveDocType(docType) {
+		if sha256Hash == "" {
+			return "", "", fmt.Errorf("sha256Hash is required")
+		}
+		return sha256Hash, fileName, nil
+	}
+
+	if sha256Hash != "" || fileName != "" {
+		return "", "", fmt.Errorf("%s documents must pass sha256Hash and fileName as transient data", docType)
+	}
+	transientMap, err := ctx.GetStub().GetTransient()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get transient map: %w", err)
+	}
+	hash, ok := transientMap["sha256Hash"]
+	if !ok || len(hash) == 0 {
+		return "", "", fmt.Errorf("sha256Hash must be provided in transient data for %s documents", docType)
+	}
+	return string(hash), string(transientMap["fileName"]), nil
+}
+
+// documentIDExists checks both public state and the private collection for a document ID.
+// The private data hash is used so that non-member peers can still detect a collision.
+func documentIDExists(ctx contractapi.TransactionContextInterface, docID string) (bool, error) {
+	docJSON, err := getAssetState(ctx, DocTypeDocument, docID)
+	if err != nil {
+		return false, fmt.Errorf("failed to read document: %w", err)
+	}
+	if docJSON != nil {
+		return true, nil
+	}
+	key, err := assetKey(ctx, DocTypeDocument, docID)
+	if err != nil {
+		return false, err
+	}
+	for _, privateKey := range []string{key, docID} {
+		privateHash, err := ctx.GetStub().GetPrivateDataHash(studentPrivateCollection, privateKey)
+		if err != nil {
+			return false, fmt.Errorf("failed to read private document hash: %w", err)
+		}
+		if privateHash != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkDocumentUploadAccess allows the student's department or admin to write documents.
+// Sensitive types are admin-only because only NITWarangalMSP belongs to the private collection.
+func checkDocumentUploadAccess(ctx contractapi.TransactionContextInterface, student *Student, docType string) error {
+	if isSensitiveDocType(docType) {
+		if err := checkMSPAccess(ctx, NITWarangalMSP); err != nil {
+			return fmt.Errorf("%s documents are stored privately: %w", docType, err)
+		}
+		return nil
+	}
+	return checkDepartmentAccess(ctx, student.Department)
+}
+
+// getClientCommonName returns the CN of the caller's certificate, which is the enrollment ID
+// the backend uses as a consent requester ID
+func getClientCommonName(ctx contractapi.TransactionContextInterface) (string, error) {
+	cert, err := ctx.GetClientIdentity().GetX509Certificate()
+	if err != nil {
+		return "", fmt.Errorf("failed to get client certificate: %w", err)
+	}
+	if cert == nil {
+		return "", fmt.Errorf("client certificate not found")
+	}
+	return cert.Subject.CommonName, nil
+}
+
+// UploadDocument stores a document hash on the blockchain. AADHAAR and PHOTO documents are
+// kept in the student private data collection and must carry their hash in transient data.
+func (s *SmartContract) UploadDocument(ctx contractapi.TransactionContextInterface,
+	docID, studentID, docType, sha256Hash, fileName, academicYear string, semester int) error {
+
+	// Verify student exists
+	student, err := s.readStudent(ctx, studentID)
+	if err != nil {
+		return err
+	}
+
+	// Validate document type
+	validDocTypes := map[string]bool{
+		"GRADE_SHEET": true, "DEGREE_CERT": true, "TRANSCRIPT": true,
+		"AADHAAR": true, "PHOTO": true, "MARKSHEET": true, "OTHER": true,
+	}
+	if !validDocTypes[docType] {
+		return fmt.Errorf("invalid document type: %s", docType)
+	}
+
+	// Access Control: student's department or admin
+	if err := checkDocumentUploadAccess(ctx, student, docType); err != nil {
+		return err
+	}
+	sensitive := isSensitiveDocType(docType)
+
+	sha256Hash, fileName, err = documentFileArgs(ctx, docType, sha256Hash, fileName)
+	if err != nil {
+		return err
+	}
+
+	exists, err := documentIDExists(ctx, docID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("document %s already exists", docID)
+	}
+
+	// Check if document with same hash already exists (deduplication)
+	existing, err := currentDocumentForHash(ctx, sensitive, sha256Hash)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("document with hash %s already exists on the ledger", sha256Hash)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	doc := DocumentUpload{
+		DocID:        docID,
+		StudentID:    studentID,
+		DocType:      DocTypeDocument,
+		DocumentType: docType,
+		SHA256Hash:   sha256Hash,
+		FileName:     fileName,
+		Semester:     semester,
+		AcademicYear: academicYear,
+		UploadedBy:   clientID,
+		UploadedAt:   now,
+		IsVerified:   false,
+		Status:       DocStatusCurrent,
+	}
+
+	// Store main document record
+	if err := s.putDocument(ctx, &doc); err != nil {
+		return err
+	}
+	if err := s.putDocumentIndexes(ctx, &doc); err != nil {
+		return err
+	}
+
+	// Emit event; sensitive documents do not publish their hash
+	eventPayload := map[string]interface{}{
+		"docId":      docID,
+		"studentId":  studentID,
+		"docType":    docType,
+		"uploadedBy": clientID,
+		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !sensitive {
+		eventPayload["sha256Hash"] = sha256Hash
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("DocumentUploaded", eventJSON)
+
+	return nil
+}
+
+// readDocument loads a document from public state, falling back to the private collection
+// for admin callers. It performs no access control.
+func (s *SmartContract) readDocument(ctx contractapi.TransactionContextInterface, docID string) (*DocumentUpload, error) {
+	docJSON, err := getAssetState(ctx, DocTypeDocument, docID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if docJSON == nil && checkMSPAccess(ctx, NITWarangalMSP) == nil {
+		docJSON, err = getPrivateAssetState(ctx, studentPrivateCollection, DocTypeDocument, docID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private document: %w", err)
+		}
+	}
+	if docJSON == nil {
+		return nil, fmt.Errorf("document %s does not exist", docID)
+	}
+
+	var doc DocumentUpload
+	if err := json.Unmarshal(docJSON, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+	// Documents written before asset types kept the document type in docType
+	if doc.DocType != DocTypeDocument {
+		if doc.DocumentType == "" {
+			doc.DocumentType = doc.DocType
+		}
+		doc.DocType = DocTypeDocument
+	}
+	// Documents uploaded before lifecycle tracking have no status and are current
+	if doc.Status == "" {
+		doc.Status = DocStatusCurrent
+	}
+	return &doc, nil
+}
+
+// GetDocument retrieves a document upload record by docID
+func (s *SmartContract) GetDocument(ctx contractapi.TransactionContextInterface, docID string) (*DocumentUpload, error) {
+	doc, err := s.readDocument(ctx, docID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeStudentReadByID(ctx, doc.StudentID); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// VerifyDocumentByHash checks if a document with the given hash exists on-chain.
+// The returned document's Status tells whether the hash is CURRENT, SUPERSEDED or RETRACTED.
+// Holding the file is what proves entitlement here, so no consent is required; sensitive
+// documents are only resolvable by admin callers since their hashes live in private data.
+func (s *SmartContract) VerifyDocumentByHash(ctx contractapi.TransactionContextInterface, sha256Hash string) (*DocumentUpload, error) {
+	stores := []bool{false}
+	if checkMSPAccess(ctx, NITWarangalMSP) == nil {
+		stores = append(stores, true)
+	}
+
+	for _, sensitive := range stores {
+		docID, err := currentDocumentForHash(ctx, sensitive, sha256Hash)
+		if err != nil {
+			return nil, err
+		}
+		if docID != "" {
+			return s.readDocument(ctx, docID)
+		}
+	}
+
+	// Not a current hash — check whether it belonged to a replaced or retracted document
+	var latest *DocumentUpload
+	for _, sensitive := range stores {
+		iter, err := getDocumentStateByPartialKey(ctx, sensitive, DocumentHashHistoryKey, []string{sha256Hash})
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up hash history: %w", err)
+		}
+
+		for iter.HasNext() {
+			kv, err := iter.Next()
+			if err != nil {
+				iter.Close()
+				return nil, fmt.Errorf("failed to iterate hash history: %w", err)
+			}
+			_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+			if err != nil || len(parts) < 2 {
+				continue
+			}
+			doc, err := s.readDocument(ctx, parts[1])
+			if err != nil {
+				continue
+			}
+			// The same file may have been registered more than once over time; report the newest
+			if latest == nil || doc.UploadedAt.After(latest.UploadedAt) {
+				latest = doc
+			}
+		}
+		iter.Close()
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no document found with hash %s — document may be tampered or not registered", sha256Hash)
+	}
+
+	return latest, nil
+}
+
+// GetDocumentsByStudent returns a page of documents uploaded for a student. Sensitive
+// documents are only included for admin callers, after the public ones.
+func (s *SmartContract) GetDocumentsByStudent(ctx contractapi.TransactionContextInterface, studentID string, bookmark string, pageSize int) (*DocumentPage, error) {
+	if err := s.authorizeStudentReadByID(ctx, studentID); err != nil {
+		return nil, err
+	}
+
+	sources := []pageSource{{objectType: DocumentKey, attributes: []string{studentID}}}
+	if checkMSPAccess(ctx, NITWarangalMSP) == nil {
+		sources = append(sources, pageSource{objectType: DocumentKey, attributes: []string{studentID}, collection: studentPrivateCollection})
+	}
+	keys, nextBookmark, err := pageIndexKeys(ctx, sources, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get documents for student %s: %w", studentID, err)
+	}
+
+	docs := []*DocumentUpload{}
+	for _, key := range keys {
+		// Extract docID from composite key
+		_, parts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		docID := parts[1]
+
+		doc, err := s.readDocument(ctx, docID)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+
+	return &DocumentPage{
+		Records:       docs,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(docs),
+		FilteredCount: len(keys) - len(docs),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// putDocument writes a document upload record under its primary key
+func (s *SmartContract) putDocument(ctx contractapi.TransactionContextInterface, doc *DocumentUpload) error {
+	doc.DocType = DocTypeDocument
+	docJSON, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal document: %w", err)
+	}
+	if isSensitiveDocType(doc.DocumentType) {
+		err = putPrivateAssetState(ctx, studentPrivateCollection, DocTypeDocument, doc.DocID, docJSON)
+	} else {
+		err = putAssetState(ctx, DocTypeDocument, doc.DocID, docJSON)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store document %s: %w", doc.DocID, err)
+	}
+	return nil
+}
+
+// putDocumentIndexes writes the document~student and document~hash entries for a current document
+func (s *SmartContract) putDocumentIndexes(ctx contractapi.TransactionContextInterface, doc *DocumentUpload) error {
+	sensitive := isSensitiveDocType(doc.DocumentType)
+
+	// Composite key: document~student for querying by student
+	studentDocKey, err := ctx.GetStub().CreateCompositeKey(DocumentKey, []string{doc.StudentID, doc.DocID})
+	if err != nil {
+		return fmt.Errorf("failed to create student document key: %w", err)
+	}
+	if err := putDocumentState(ctx, sensitive, studentDocKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to put student document key: %w", err)
+	}
+
+	// Composite key: document~hash for hash-based lookup
+	hashKey, err := ctx.GetStub().CreateCompositeKey(DocumentHashKey, []string{doc.SHA256Hash, doc.DocID})
+	if err != nil {
+		return fmt.Errorf("failed to create hash key: %w", err)
+	}
+	if err := putDocumentState(ctx, sensitive, hashKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to put hash key: %w", err)
+	}
+	return nil
+}
+
+// retireDocumentHash frees a document's document~hash entry so the hash no longer reads as
+// current, and keeps it in document~hashhistory so VerifyDocumentByHash can still report it
+func (s *SmartContract) retireDocumentHash(ctx contractapi.TransactionContextInterface, doc *DocumentUpload) error {
+	sensitive := isSensitiveDocType(doc.DocumentType)
+
+	hashKey, err := ctx.GetStub().CreateCompositeKey(DocumentHashKey, []string{doc.SHA256Hash, doc.DocID})
+	if err != nil {
+		return fmt.Errorf("failed to create hash key: %w", err)
+	}
+	if err := delDocumentState(ctx, sensitive, hashKey); err != nil {
+		return fmt.Errorf("failed to delete hash key: %w", err)
+	}
+	// Entries written before index values became markers are keyed by the hash alone
+	legacyHashKey, err := ctx.GetStub().CreateCompositeKey(DocumentHashKey, []string{doc.SHA256Hash})
+	if err != nil {
+		return fmt.Errorf("failed to create hash key: %w", err)
+	}
+	if err := delDocumentState(ctx, sensitive, legacyHashKey); err != nil {
+		return fmt.Errorf("failed to delete hash key: %w", err)
+	}
+
+	historyKey, err := ctx.GetStub().CreateCompositeKey(DocumentHashHistoryKey, []string{doc.SHA256Hash, doc.DocID})
+	if err != nil {
+		return fmt.Errorf("failed to create hash history key: %w", err)
+	}
+	if err := putDocumentState(ctx, sensitive, historyKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to put hash history key: %w", err)
+	}
+	return nil
+}
+
+// VerifyDocument marks a current document as verified by the student's department or admin
+func (s *SmartContract) VerifyDocument(ctx contractapi.TransactionContextInterface, docID string) error {
+	doc, err := s.readDocument(ctx, docID)
+	if err != nil {
+		return err
+	}
+
+	student, err := s.readStudent(ctx, doc.StudentID)
+	if err != nil {
+		return err
+	}
+	if err := checkDocumentUploadAccess(ctx, student, doc.DocumentType); err != nil {
+		return err
+	}
+
+	if doc.Status != DocStatusCurrent {
+		return fmt.Errorf("cannot verify document %s with status %s", docID, doc.Status)
+	}
+	if doc.IsVerified {
+		return fmt.Errorf("document %s is already verified", docID)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %w", err)
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	doc.IsVerified = true
+	doc.VerifiedBy = clientID
+	doc.VerifiedAt = now
+
+	if err := s.putDocument(ctx, doc); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{
+		"docId":      docID,
+		"studentId":  doc.StudentID,
+		"verifiedBy": clientID,
+		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !isSensitiveDocType(doc.DocumentType) {
+		eventPayload["sha256Hash"] = doc.SHA256Hash
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("DocumentVerified", eventJSON)
+
+	return nil
+}
+
+// ReplaceDocument registers a new version of a document. The old version is marked SUPERSEDED
+// and linked to the new one, and its hash is released from the document~hash index.
+func (s *SmartContract) ReplaceDocument(ctx contractapi.TransactionContextInterface,
+	oldDocID, newDocID, sha256Hash, fileName string) error {
+
+	oldDoc, err := s.readDocument(ctx, oldDocID)
+	if err != nil {
+		return err
+	}
+
+	student, err := s.readStudent(ctx, oldDoc.StudentID)
+	if err != nil {
+		return err
+	}
+	if err := checkDocumentUploadAccess(ctx, student, oldDoc.DocumentType); err != nil {
+		return err
+	}
+
+	if oldDoc.Status != DocStatusCurrent {
+		return fmt.Errorf("only CURRENT documents can be replaced, document %s is %s", oldDocID, oldDoc.Status)
+	}
+	sensitive := isSensitiveDocType(oldDoc.DocumentType)
+
+	sha256Hash, fileName, err = documentFileArgs(ctx, oldDoc.DocumentType, sha256Hash, fileName)
+	if err != nil {
+		return err
+	}
+	if sha256Hash == oldDoc.SHA256Hash {
+		return fmt.Errorf("replacement must have a different hash than document %s", oldDocID)
+	}
+
+	exists, err := documentIDExists(ctx, newDocID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("document %s already exists", newDocID)
+	}
+
+	existing, err := currentDocumentForHash(ctx, sensitive, sha256Hash)
+	if err != nil {
+		return err
+	}
+	if existing != "" {
+		return fmt.Errorf("document with hash %s already exists on the ledger", sha256Hash)
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %w", err)
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	newDoc := DocumentUpload{
+		DocID:        newDocID,
+		StudentID:    oldDoc.StudentID,
+		DocType:      DocTypeDocument,
+		DocumentType: oldDoc.DocumentType,
+		SHA256Hash:   sha256Hash,
+		FileName:     fileName,
+		Semester:     oldDoc.Semester,
+		AcademicYear: oldDoc.AcademicYear,
+		UploadedBy:   clientID,
+		UploadedAt:   now,
+		IsVerified:   false,
+		Status:       DocStatusCurrent,
+		Supersedes:   oldDocID,
+	}
+	if err := s.putDocument(ctx, &newDoc); err != nil {
+		return err
+	}
+	if err := s.putDocumentIndexes(ctx, &newDoc); err != nil {
+		return err
+	}
+
+	// Retire the old version
+	if err := s.retireDocumentHash(ctx, oldDoc); err != nil {
+		return err
+	}
+	oldDoc.Status = DocStatusSuperseded
+	oldDoc.SupersededBy = newDocID
+	if err := s.putDocument(ctx, oldDoc); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{
+		"oldDocId":   oldDocID,
+		"newDocId":   newDocID,
+		"studentId":  newDoc.StudentID,
+		"docType":    newDoc.DocumentType,
+		"replacedBy": clientID,
+		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !sensitive {
+		eventPayload["sha256Hash"] = sha256Hash
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("DocumentReplaced", eventJSON)
+
+	return nil
+}
+
+// RetractDocument withdraws a document with a mandatory reason. Its hash will then
+// verify as RETRACTED instead of CURRENT.
+func (s *SmartContract) RetractDocument(ctx contractapi.TransactionContextInterface, docID, reason string) error {
+	doc, err := s.readDocument(ctx, docID)
+	if err != nil {
+		return err
+	}
+
+	student, err := s.readStudent(ctx, doc.StudentID)
+	if err != nil {
+		return err
+	}
+	if err := checkDocumentUploadAccess(ctx, student, doc.DocumentType); err != nil {
+		return err
+	}
+
+	if doc.Status == DocStatusRetracted {
+		return fmt.Errorf("document %s is already retracted", docID)
+	}
+
+	// Validate reason
+	if len(reason) < 10 {
+		return fmt.Errorf("retraction reason must be at least 10 characters")
+	}
+
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %w", err)
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	// A superseded version has already released its hash
+	if doc.Status == DocStatusCurrent {
+		if err := s.retireDocumentHash(ctx, doc); err != nil {
+			return err
+		}
+	}
+
+	oldStatus := doc.Status
+	doc.Status = DocStatusRetracted
+	doc.RetractedBy = clientID
+	doc.RetractedAt = now
+	doc.RetractionReason = reason
+	if err := s.putDocument(ctx, doc); err != nil {
+		return err
+	}
+
+	eventPayload := map[string]interface{}{
+		"docId":       docID,
+		"studentId":   doc.StudentID,
+		"oldStatus":   oldStatus,
+		"retractedBy": clientID,
+		"reason":      reason,
+		"timestamp":   now.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if !isSensitiveDocType(doc.DocumentType) {
+		eventPayload["sha256Hash"] = doc.SHA256Hash
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("DocumentRetracted", eventJSON)
 
-	// Composite key prefixes for new entities
-	ApprovalKey          = "approval~record"
-	DocumentKey          = "document~student"
-	DocumentHashKey      = "document~hash"
-	SemesterRegKey       = "semreg~student"
-)
+	return nil
+}
+
+// ============================================================
+// SEMESTER REGISTRATION
+// ============================================================
+
+// RegisterForSemester registers a student for a semester
+func (s *SmartContract) RegisterForSemester(ctx contractapi.TransactionContextInterface,
+	regID, studentID, academicYear, facultyAdvisor string, semester int) error {
+
+	// Verify student exists and belongs to the caller's department
+	student, err := s.readStudent(ctx, studentID)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartmentAccess(ctx, student.Department); err != nil {
+		return err
+	}
+
+	if err := validateSemester(semester); err != nil {
+		return err
+	}
+
+	if err := s.checkStudentStatusFor(ctx, "RegisterForSemester", student, semester); err != nil {
+		return err
+	}
+
+	// Check if registration already exists
+	existingJSON, _ := getAssetState(ctx, DocTypeRegistration, regID)
+	if existingJSON != nil {
+		return fmt.Errorf("registration %s already exists", regID)
+	}
+
+	clientID, _ := ctx.GetClientIdentity().GetID()
+	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	reg := SemesterRegistration{
+		DocType:        DocTypeRegistration,
+		RegID:          regID,
+		StudentID:      studentID,
+		Semester:       semester,
+		AcademicYear:   academicYear,
+		FacultyAdvisor: facultyAdvisor,
+		Status:         "REGISTERED",
+		RegisteredBy:   clientID,
+		RegisteredAt:   now,
+		UpdatedAt:      now,
+	}
+
+	regJSON, err := json.Marshal(reg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registration: %w", err)
+	}
+
+	if err := putAssetState(ctx, DocTypeRegistration, regID, regJSON); err != nil {
+		return fmt.Errorf("failed to store registration: %w", err)
+	}
+
+	// Composite key for student semester registrations
+	semRegKey, _ := ctx.GetStub().CreateCompositeKey(SemesterRegKey, []string{studentID, fmt.Sprintf("%d", semester), regID})
+	ctx.GetStub().PutState(semRegKey, []byte{0x00})
+
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"regId":          regID,
+		"studentId":      studentID,
+		"semester":       semester,
+		"academicYear":   academicYear,
+		"facultyAdvisor": facultyAdvisor,
+		"registeredBy":   clientID,
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("StudentRegisteredForSemester", eventJSON)
+
+	return nil
+}
+
+// GetSemesterRegistration retrieves a semester registration
+func (s *SmartContract) GetSemesterRegistration(ctx contractapi.TransactionContextInterface, regID string) (*SemesterRegistration, error) {
+	regJSON, err := getAssetState(ctx, DocTypeRegistration, regID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registration: %w", err)
+	}
+	if regJSON == nil {
+		return nil, fmt.Errorf("registration %s does not exist", regID)
+	}
+
+	var reg SemesterRegistration
+	if err := json.Unmarshal(regJSON, &reg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal registration: %w", err)
+	}
+	if err := s.authorizeStudentReadByID(ctx, reg.StudentID); err != nil {
+		return nil, err
+	}
+	return &reg, nil
+}
+
+// GetSemesterRegistrationsByStudent returns a page of semester registrations for a student
+func (s *SmartContract) GetSemesterRegistrationsByStudent(ctx contractapi.TransactionContextInterface, studentID string, bookmark string, pageSize int) (*RegistrationPage, error) {
+	if err := s.authorizeStudentReadByID(ctx, studentID); err != nil {
+		return nil, err
+	}
+
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: SemesterRegKey, attributes: []string{studentID}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registrations for student %s: %w", studentID, err)
+	}
+
+	registrations := []*SemesterRegistration{}
+	for _, key := range keys {
+		_, parts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+		regID := parts[2]
+
+		reg, err := s.GetSemesterRegistration(ctx, regID)
+		if err != nil {
+			continue
+		}
+		registrations = append(registrations, reg)
+	}
+
+	return &RegistrationPage{
+		Records:       registrations,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(registrations),
+		FilteredCount: len(keys) - len(registrations),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// ============================================================
+// SPRINT 3 ADDITIONS: CONSENT MANAGEMENT + DOCUMENT STATUS
+// ============================================================
+
+// ConsentRecord stores a student's consent grant on the ledger
+type ConsentRecord struct {
+	ConsentID   string `json:"consentId"`
+	StudentID   string `json:"studentId"`
+	RequesterID string `json:"requesterId"`
+	Scope       string `json:"scope"`   // SEMESTER | FULL_RECORD
+	Status      string `json:"status"`  // ACTIVE | REVOKED
+	GrantedBy   string `json:"grantedBy"`
+	GrantedAt   string `json:"grantedAt"`
+	RevokedAt   string `json:"revokedAt,omitempty"`
+	RevokedBy   string `json:"revokedBy,omitempty"`
+	ExpiresAt   string `json:"expiresAt,omitempty"`
+}
+
+const ConsentKeyPrefix = "CONSENT"
+
+// checkConsentOwner restricts student callers to consents over their own records;
+// administration may act on a student's behalf
+func checkConsentOwner(ctx contractapi.TransactionContextInterface, studentID string) error {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if caller.isStudent() && caller.RollNumber != studentID {
+		return fmt.Errorf("unauthorized: students can only manage consents for their own records")
+	}
+	return nil
+}
+
+// GrantConsent — student grants a requester (employer/institution) access to their records
+func (s *SmartContract) GrantConsent(ctx contractapi.TransactionContextInterface,
+	consentID, studentID, requesterID, scope, grantedBy, grantedAt string) error {
+
+	if err := checkConsentOwner(ctx, studentID); err != nil {
+		return err
+	}
+
+	// Validate scope
+	if scope != "SEMESTER" && scope != "FULL_RECORD" {
+		return fmt.Errorf("invalid scope '%s': must be SEMESTER or FULL_RECORD", scope)
+	}
+
+	// Check for existing active consent between same pair
+	existing, _ := s.CheckConsent(ctx, studentID, requesterID)
+	if existing {
+		return fmt.Errorf("active consent already exists for student %s and requester %s", studentID, requesterID)
+	}
+
+	consent := ConsentRecord{
+		ConsentID:   consentID,
+		StudentID:   studentID,
+		RequesterID: requesterID,
+		Scope:       scope,
+		Status:      "ACTIVE",
+		GrantedBy:   grantedBy,
+		GrantedAt:   grantedAt,
+	}
+
+	consentJSON, err := json.Marshal(consent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal consent: %w", err)
+	}
+
+	// Primary key: CONSENT~consentID
+	key, err := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
+	if err != nil {
+		return fmt.Errorf("failed to create consent key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(key, consentJSON); err != nil {
+		return fmt.Errorf("failed to store consent: %w", err)
+	}
+
+	// Index key for lookup by student+requester: CONSENT_IDX~studentID~requesterID~consentID
+	idxKey, _ := ctx.GetStub().CreateCompositeKey("CONSENT_IDX", []string{studentID, requesterID, consentID})
+	_ = ctx.GetStub().PutState(idxKey, []byte{0x00})
+
+	// Emit event
+	_ = ctx.GetStub().SetEvent("ConsentGranted", consentJSON)
+
+	return nil
+}
+
+// RevokeConsent — student revokes a previously granted consent
+func (s *SmartContract) RevokeConsent(ctx contractapi.TransactionContextInterface,
+	consentID, revokedBy, revokedAt string) error {
+
+	key, err := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
+	if err != nil {
+		return fmt.Errorf("failed to create consent key: %w", err)
+	}
+
+	consentJSON, err := ctx.GetStub().GetState(key)
+	if err != nil || consentJSON == nil {
+		return fmt.Errorf("consent %s not found", consentID)
+	}
+
+	var consent ConsentRecord
+	if err := json.Unmarshal(consentJSON, &consent); err != nil {
+		return fmt.Errorf("failed to unmarshal consent: %w", err)
+	}
+
+	if err := checkConsentOwner(ctx, consent.StudentID); err != nil {
+		return err
+	}
+
+	if consent.Status == "REVOKED" {
+		return fmt.Errorf("consent %s is already revoked", consentID)
+	}
+
+	consent.Status = "REVOKED"
+	consent.RevokedBy = revokedBy
+	consent.RevokedAt = revokedAt
+
+	updatedJSON, err := json.Marshal(consent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal updated consent: %w", err)
+	}
+
+	if err := ctx.GetStub().PutState(key, updatedJSON); err != nil {
+		return fmt.Errorf("failed to update consent: %w", err)
+	}
+
+	_ = ctx.GetStub().SetEvent("ConsentRevoked", updatedJSON)
+
+	return nil
+}
+
+// CheckConsent — returns true if an active consent exists for the studentID+requesterID pair
+func (s *SmartContract) CheckConsent(ctx contractapi.TransactionContextInterface,
+	studentID, requesterID string) (bool, error) {
+
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey("CONSENT_IDX", []string{studentID, requesterID})
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			continue
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+		consentID := parts[2]
+		key, _ := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
+		consentJSON, err := ctx.GetStub().GetState(key)
+		if err != nil || consentJSON == nil {
+			continue
+		}
+		var consent ConsentRecord
+		if err := json.Unmarshal(consentJSON, &consent); err != nil {
+			continue
+		}
+		if consent.Status == "ACTIVE" {
+			return true, nil
+		}
+	}
 
-// ApprovalStep represents a single approval in the multi-party chain
-type ApprovalStep struct {
-	Role       string    `json:"role"`
-	ApprovedBy string    `json:"approvedBy"`
-	Timestamp  time.Time `json:"timestamp"`
-	Comment    string    `json:"comment"`
-	TxID       string    `json:"txId"`
+	return false, nil
 }
 
-// ApprovalRecord holds full approval chain for an academic record
-type ApprovalRecord struct {
-	RecordID      string          `json:"recordId"`
-	StudentID     string          `json:"studentId"`
-	Department    string          `json:"department"`
-	Semester      int             `json:"semester"`
-	CurrentStatus string          `json:"currentStatus"`
-	ApprovalChain []ApprovalStep  `json:"approvalChain"`
-	Rejections    []ApprovalStep  `json:"rejections"`
-	CreatedAt     time.Time       `json:"createdAt"`
-	UpdatedAt     time.Time       `json:"updatedAt"`
-}
+// GetConsentsByStudent — returns a page of consent records (active and revoked) for a student
+func (s *SmartContract) GetConsentsByStudent(ctx contractapi.TransactionContextInterface,
+	studentID string, bookmark string, pageSize int) (*ConsentPage, error) {
 
-// DocumentUpload represents a document uploaded and hashed on the blockchain
-type DocumentUpload struct {
-	DocID        string    `json:"docId"`
-	StudentID    string    `json:"studentId"`
-	DocType      string    `json:"docType"`   // GRADE_SHEET, DEGREE_CERT, TRANSCRIPT, AADHAAR, PHOTO, OTHER
-	SHA256Hash   string    `json:"sha256Hash"`
-	FileName     string    `json:"fileName"`
-	Semester     int       `json:"semester"`   // 0 = not semester-specific
-	AcademicYear string    `json:"academicYear"`
-	UploadedBy   string    `json:"uploadedBy"`
-	UploadedAt   time.Time `json:"uploadedAt"`
-	IsVerified   bool      `json:"isVerified"`
-	VerifiedBy   string    `json:"verifiedBy"`
+	if err := s.authorizeStudentReadByID(ctx, studentID); err != nil {
+		return nil, err
+	}
+
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: "CONSENT_IDX", attributes: []string{studentID}}}, bookmark, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	consents := []*ConsentRecord{}
+	seen := map[string]bool{}
+
+	for _, indexKey := range keys {
+		_, parts, err := ctx.GetStub().SplitCompositeKey(indexKey)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+		consentID := parts[2]
+		if seen[consentID] {
+			continue
+		}
+		seen[consentID] = true
+
+		key, _ := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
+		consentJSON, err := ctx.GetStub().GetState(key)
+		if err != nil || consentJSON == nil {
+			continue
+		}
+		var c ConsentRecord
+		if err := json.Unmarshal(consentJSON, &c); err != nil {
+			continue
+		}
+		consents = append(consents, &c)
+	}
+
+	return &ConsentPage{
+		Records:       consents,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(consents),
+		FilteredCount: len(keys) - len(consents),
+		HasMore:       nextBookmark != "",
+	}, nil
 }
 
-// SemesterRegistration represents a student's semester registration
-type SemesterRegistration struct {
-	RegID          string    `json:"regId"`
-	StudentID      string    `json:"studentId"`
-	Semester       int       `json:"semester"`
-	AcademicYear   string    `json:"academicYear"`
-	FacultyAdvisor string    `json:"facultyAdvisor"`
-	Status         string    `json:"status"` // REGISTERED, COMPLETED, DROPPED
-	RegisteredBy   string    `json:"registeredBy"`
-	RegisteredAt   time.Time `json:"registeredAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+// UpdateDocumentStatus — advances or reverts a document through the 5-stage pipeline.
+// Valid transitions:
+//   UPLOADED → UNDER_REVIEW → AUTHENTICATED → APPROVED → ON_CHAIN
+//   Any stage → UPLOADED (return for re-authentication)
+func (s *SmartContract) UpdateDocumentStatus(ctx contractapi.TransactionContextInterface,
+	docID, newStatus, updatedBy string) error {
+
+	// Valid pipeline statuses
+	validStatuses := map[string]bool{
+		"UPLOADED":      true,
+		"UNDER_REVIEW":  true,
+		"AUTHENTICATED": true,
+		"APPROVED":      true,
+		"ON_CHAIN":      true,
+	}
+	if !validStatuses[newStatus] {
+		return fmt.Errorf("invalid document status '%s'", newStatus)
+	}
+
+	doc, err := s.readDocument(ctx, docID)
+	if err != nil {
+		return err
+	}
+	student, err := s.readStudent(ctx, doc.StudentID)
+	if err != nil {
+		return err
+	}
+	if err := checkDepartmentAccess(ctx, student.Department); err != nil {
+		return err
+	}
+
+	// Validate ordering when advancing (regression to UPLOADED always allowed)
+	stageOrder := map[string]int{
+		"UPLOADED":      0,
+		"UNDER_REVIEW":  1,
+		"AUTHENTICATED": 2,
+		"APPROVED":      3,
+		"ON_CHAIN":      4,
+	}
+	currentStatus := doc.DocumentStatus
+	if currentStatus == "" {
+		currentStatus = "UPLOADED"
+	}
+
+	if newStatus != "UPLOADED" {
+		if stageOrder[newStatus] != stageOrder[currentStatus]+1 {
+			return fmt.Errorf("invalid transition from %s to %s: must advance exactly one stage at a time", currentStatus, newStatus)
+		}
+	}
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+
+	// Update fields
+	doc.DocumentStatus = newStatus
+	doc.StatusUpdatedBy = updatedBy
+	doc.StatusUpdatedAt = time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+
+	if err := s.putDocument(ctx, doc); err != nil {
+		return fmt.Errorf("failed to update document status: %w", err)
+	}
+
+	eventPayload := map[string]string{
+		"docId":     docID,
+		"oldStatus": currentStatus,
+		"newStatus": newStatus,
+		"updatedBy": updatedBy,
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	_ = ctx.GetStub().SetEvent("DocumentStatusUpdated", eventJSON)
+
+	return nil
 }
 
 // ============================================================
-// HELPER: getOrCreateApprovalRecord
+// ASSET KEYS
 // ============================================================
 
-func (s *SmartContract) getOrCreateApprovalRecord(ctx contractapi.TransactionContextInterface, recordID string) (*ApprovalRecord, error) {
-	approvalKey, err := ctx.GetStub().CreateCompositeKey(ApprovalKey, []string{recordID})
+// assetKey returns the typed key of a primary asset
+func assetKey(ctx contractapi.TransactionContextInterface, docType, id string) (string, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(docType, []string{id})
 	if err != nil {
-		return nil, fmt.Errorf("failed to create approval key: %w", err)
+		return "", fmt.Errorf("failed to create %s key: %w", docType, err)
 	}
+	return key, nil
+}
 
-	approvalJSON, err := ctx.GetStub().GetState(approvalKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read approval record: %w", err)
+// assetTypeOf identifies the asset type of a stored value from its docType field or, for
+// values written before asset types existed, from the fields only that type has
+func assetTypeOf(value []byte) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return ""
+	}
+	var docType string
+	if raw, ok := fields["docType"]; ok {
+		_ = json.Unmarshal(raw, &docType)
+	}
+	for _, assetType := range assetTypes {
+		if docType == assetType {
+			return docType
+		}
 	}
 
-	if approvalJSON != nil {
-		var ar ApprovalRecord
-		if err := json.Unmarshal(approvalJSON, &ar); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal approval record: %w", err)
+	has := func(names ...string) bool {
+		for _, name := range names {
+			if _, ok := fields[name]; !ok {
+				return false
+			}
+		}
+		return true
+	}
+	switch {
+	case has("rollNumber", "enrollmentYear"):
+		return DocTypeStudent
+	case has("recordId", "courses"):
+		return DocTypeRecord
+	case has("certificateId", "pdfHash"):
+		return DocTypeCertificate
+	case has("docId", "sha256Hash"):
+		return DocTypeDocument
+	case has("regId", "semester"):
+		return DocTypeRegistration
+	case has("offeringId", "courseCode"):
+		return DocTypeCourseOffering
+	case has("departmentId", "departmentName"):
+		return DocTypeDepartment
+	}
+	return ""
+}
+
+// stampDocType makes sure a value carries the given docType. Documents written before asset
+// types kept their document type (AADHAAR, GRADE_SHEET, ...) in docType; it is moved to
+// documentType so that stamping never loses it.
+func stampDocType(value []byte, docType string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return nil, fmt.Errorf("%s value is not a JSON object: %w", docType, err)
+	}
+	var current string
+	if raw, ok := fields["docType"]; ok {
+		_ = json.Unmarshal(raw, &current)
+	}
+	if current == docType {
+		return value, nil
+	}
+	if docType == DocTypeDocument && current != "" {
+		var documentType string
+		if raw, ok := fields["documentType"]; ok {
+			_ = json.Unmarshal(raw, &documentType)
+		}
+		if documentType == "" {
+			fields["documentType"], _ = json.Marshal(current)
 		}
-		return &ar, nil
 	}
+	fields["docType"], _ = json.Marshal(docType)
+	return json.Marshal(fields)
+}
 
-	// Fetch the academic record for context
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return nil, fmt.Errorf("academic record %s not found", recordID)
+// getAssetState reads a primary asset from its typed key, falling back to the untyped
+// legacy key for data not yet moved by MigrateAssetKeys. Returns nil if there is none.
+func getAssetState(ctx contractapi.TransactionContextInterface, docType, id string) ([]byte, error) {
+	key, err := assetKey(ctx, docType, id)
+	if err != nil {
+		return nil, err
 	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal academic record: %w", err)
+	value, err := ctx.GetStub().GetState(key)
+	if err != nil || value != nil || id == "" {
+		return value, err
 	}
 
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-
-	ar := ApprovalRecord{
-		RecordID:      recordID,
-		StudentID:     rec.StudentID,
-		Department:    rec.Department,
-		Semester:      rec.Semester,
-		CurrentStatus: rec.Status,
-		ApprovalChain: []ApprovalStep{},
-		Rejections:    []ApprovalStep{},
-		CreatedAt:     now,
-		UpdatedAt:     now,
+	legacy, err := ctx.GetStub().GetState(id)
+	if err != nil || legacy == nil {
+		return nil, err
 	}
-	return &ar, nil
+	if assetTypeOf(legacy) != docType {
+		return nil, nil
+	}
+	return legacy, nil
 }
 
-func (s *SmartContract) saveApprovalRecord(ctx contractapi.TransactionContextInterface, ar *ApprovalRecord) error {
-	approvalKey, err := ctx.GetStub().CreateCompositeKey(ApprovalKey, []string{ar.RecordID})
+// putAssetState writes a primary asset under its typed key and removes its legacy copy
+func putAssetState(ctx contractapi.TransactionContextInterface, docType, id string, value []byte) error {
+	value, err := stampDocType(value, docType)
 	if err != nil {
-		return fmt.Errorf("failed to create approval key: %w", err)
+		return err
 	}
-	arJSON, err := json.Marshal(ar)
+	key, err := assetKey(ctx, docType, id)
 	if err != nil {
-		return fmt.Errorf("failed to marshal approval record: %w", err)
+		return err
+	}
+	if err := ctx.GetStub().PutState(key, value); err != nil {
+		return err
 	}
-	return ctx.GetStub().PutState(approvalKey, arJSON)
-}
 
-// updateRecordStatus updates the AcademicRecord's status field and composite keys
-func (s *SmartContract) updateRecordStatus(ctx contractapi.TransactionContextInterface, recordID, newStatus string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("academic record %s not found", recordID)
+	// Another asset type may own the same untyped key, so only remove a copy of this asset
+	if id == "" {
+		return nil
 	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+	legacy, err := ctx.GetStub().GetState(id)
+	if err != nil {
+		return fmt.Errorf("failed to read legacy key %s: %w", id, err)
+	}
+	if legacy != nil && assetTypeOf(legacy) == docType {
+		if err := ctx.GetStub().DelState(id); err != nil {
+			return fmt.Errorf("failed to delete legacy key %s: %w", id, err)
+		}
 	}
+	return nil
+}
 
-	oldStatus := rec.Status
+// getPrivateAssetState is getAssetState for a private data collection
+func getPrivateAssetState(ctx contractapi.TransactionContextInterface, collection, docType, id string) ([]byte, error) {
+	key, err := assetKey(ctx, docType, id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ctx.GetStub().GetPrivateData(collection, key)
+	if err != nil || value != nil || id == "" {
+		return value, err
+	}
 
-	// Update composite status key
-	oldStatusKey, _ := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{oldStatus, rec.StudentID, recordID})
-	ctx.GetStub().DelState(oldStatusKey)
+	legacy, err := ctx.GetStub().GetPrivateData(collection, id)
+	if err != nil || legacy == nil {
+		return nil, err
+	}
+	if assetTypeOf(legacy) != docType {
+		return nil, nil
+	}
+	return legacy, nil
+}
 
-	newStatusKey, _ := ctx.GetStub().CreateCompositeKey(RecordStatusKey, []string{newStatus, rec.StudentID, recordID})
-	ctx.GetStub().PutState(newStatusKey, []byte{0x00})
+// putPrivateAssetState is putAssetState for a private data collection
+func putPrivateAssetState(ctx contractapi.TransactionContextInterface, collection, docType, id string, value []byte) error {
+	value, err := stampDocType(value, docType)
+	if err != nil {
+		return err
+	}
+	key, err := assetKey(ctx, docType, id)
+	if err != nil {
+		return err
+	}
+	if err := ctx.GetStub().PutPrivateData(collection, key, value); err != nil {
+		return err
+	}
 
-	rec.Status = newStatus
-	updatedJSON, _ := json.Marshal(rec)
-	return ctx.GetStub().PutState(recordID, updatedJSON)
+	if id == "" {
+		return nil
+	}
+	legacy, err := ctx.GetStub().GetPrivateData(collection, id)
+	if err != nil {
+		return fmt.Errorf("failed to read legacy key %s: %w", id, err)
+	}
+	if legacy != nil && assetTypeOf(legacy) == docType {
+		if err := ctx.GetStub().DelPrivateData(collection, id); err != nil {
+			return fmt.Errorf("failed to delete legacy key %s: %w", id, err)
+		}
+	}
+	return nil
 }
 
-// ============================================================
-// APPROVAL WORKFLOW FUNCTIONS
-// ============================================================
+// scanAssets calls fn for every public asset of the given types, whether stored under its
+// typed key or still under a legacy key
+func scanAssets(ctx contractapi.TransactionContextInterface, docTypes []string, fn func(docType, key string, value []byte) error) error {
+	wanted := map[string]bool{}
+	for _, docType := range docTypes {
+		wanted[docType] = true
 
-// GetApprovalStatus retrieves the full approval chain for a record
-func (s *SmartContract) GetApprovalStatus(ctx contractapi.TransactionContextInterface, recordID string) (*ApprovalRecord, error) {
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+		iter, err := ctx.GetStub().GetStateByPartialCompositeKey(docType, []string{})
+		if err != nil {
+			return fmt.Errorf("failed to scan %s assets: %w", docType, err)
+		}
+		for iter.HasNext() {
+			kv, err := iter.Next()
+			if err != nil {
+				iter.Close()
+				return fmt.Errorf("failed to iterate %s assets: %w", docType, err)
+			}
+			if err := fn(docType, kv.Key, kv.Value); err != nil {
+				iter.Close()
+				return err
+			}
+		}
+		iter.Close()
+	}
+
+	// An empty range covers every simple key, which is where legacy assets live
+	iter, err := ctx.GetStub().GetStateByRange("", "")
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to scan legacy keys: %w", err)
+	}
+	defer iter.Close()
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate legacy keys: %w", err)
+		}
+		docType := assetTypeOf(kv.Value)
+		if !wanted[docType] {
+			continue
+		}
+		if err := fn(docType, kv.Key, kv.Value); err != nil {
+			return err
+		}
 	}
-	return ar, nil
+	return nil
 }
 
-// SubmitForApproval moves a DRAFT record to SUBMITTED status (department submits)
-func (s *SmartContract) SubmitForApproval(ctx contractapi.TransactionContextInterface, recordID string) error {
-	// DepartmentsMSP or NITWarangalMSP can submit
-	clientMSPID, err := ctx.GetClientIdentity().GetMSPID()
+// KeyMigrationReport describes one batch of MigrateAssetKeys
+type KeyMigrationReport struct {
+	Migrated map[string]int `json:"migrated"` // Assets moved to typed keys, by asset type, and sensitive documents moved to private data
+	Skipped  []string       `json:"skipped"`  // Legacy keys that hold no asset and were left in place
+	NextKey  string         `json:"nextKey"`  // Pass as startKey to continue, "" when done
+	Done     bool           `json:"done"`
+	TxID     string         `json:"txId"`
+}
+
+// privatizeDocument moves a sensitive document that an upload from before sensitive metadata
+// was kept private left in public state to the student private collection, together with its
+// document~student, document~hash and document~hashhistory entries. It reports whether the
+// document was sensitive; other documents are left alone.
+func (s *SmartContract) privatizeDocument(ctx contractapi.TransactionContextInterface, key string, value []byte) (bool, error) {
+	value, err := stampDocType(value, DocTypeDocument)
 	if err != nil {
-		return fmt.Errorf("failed to get MSP ID: %w", err)
+		return false, err
+	}
+	var doc DocumentUpload
+	if err := json.Unmarshal(value, &doc); err != nil {
+		return false, fmt.Errorf("failed to unmarshal document %s: %w", key, err)
 	}
-	if clientMSPID != DepartmentsMSP && clientMSPID != NITWarangalMSP {
-		return fmt.Errorf("unauthorized: only departments or admin can submit records for approval")
+	if !isSensitiveDocType(doc.DocumentType) {
+		return false, nil
 	}
 
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
+	if err := putPrivateAssetState(ctx, studentPrivateCollection, DocTypeDocument, doc.DocID, value); err != nil {
+		return false, fmt.Errorf("failed to store private document %s: %w", doc.DocID, err)
 	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+	if err := ctx.GetStub().DelState(key); err != nil {
+		return false, fmt.Errorf("failed to delete public document %s: %w", doc.DocID, err)
 	}
 
-	if rec.Status != RecordDraft {
-		return fmt.Errorf("only DRAFT records can be submitted for approval, current status: %s", rec.Status)
+	indexes := [][]string{
+		{DocumentKey, doc.StudentID, doc.DocID},
+		{DocumentHashKey, doc.SHA256Hash, doc.DocID},
+		{DocumentHashKey, doc.SHA256Hash}, // entry from before index values became markers
+		{DocumentHashHistoryKey, doc.SHA256Hash, doc.DocID},
 	}
+	for _, index := range indexes {
+		indexKey, err := ctx.GetStub().CreateCompositeKey(index[0], index[1:])
+		if err != nil {
+			return false, fmt.Errorf("failed to create %s key: %w", index[0], err)
+		}
+		marker, err := ctx.GetStub().GetState(indexKey)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s entry: %w", index[0], err)
+		}
+		// A legacy hash entry holds the ID of the document it belongs to
+		if marker == nil || (len(index) == 2 && string(marker) != doc.DocID) {
+			continue
+		}
+		if err := ctx.GetStub().PutPrivateData(studentPrivateCollection, indexKey, marker); err != nil {
+			return false, fmt.Errorf("failed to store private %s entry: %w", index[0], err)
+		}
+		if err := ctx.GetStub().DelState(indexKey); err != nil {
+			return false, fmt.Errorf("failed to delete public %s entry: %w", index[0], err)
+		}
+	}
+	return true, nil
+}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-
-	// Update record status
-	if err := s.updateRecordStatus(ctx, recordID, RecordSubmitted); err != nil {
-		return fmt.Errorf("failed to update record status: %w", err)
+// MigrateAssetKeys moves assets stored under untyped legacy keys to their typed keys, adding
+// docType (a legacy document's type moves to documentType, see stampDocType), in batches of
+// at most limit keys starting at startKey. Sensitive (AADHAAR, PHOTO) documents found in public
+// state go to the student private collection instead. The first batch (empty startKey) also
+// moves sensitive documents already under typed public keys and those in the private
+// collection. Reads fall back to legacy keys until the migration is done, so it can run while
+// the network is in use.
+func (s *SmartContract) MigrateAssetKeys(ctx contractapi.TransactionContextInterface, startKey string, limit int) (*KeyMigrationReport, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 200
 	}
+	report := &KeyMigrationReport{Migrated: map[string]int{}, Skipped: []string{}, TxID: ctx.GetStub().GetTxID()}
 
-	// Initialize approval record
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	iter, err := ctx.GetStub().GetStateByRange(startKey, "")
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to scan legacy keys: %w", err)
 	}
-	ar.CurrentStatus = RecordSubmitted
-	ar.UpdatedAt = now
+	defer iter.Close()
 
-	step := ApprovalStep{
-		Role:       "department",
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    "Submitted for approval",
-		TxID:       ctx.GetStub().GetTxID(),
+	processed := 0
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate legacy keys: %w", err)
+		}
+		if processed == limit {
+			report.NextKey = kv.Key
+			break
+		}
+		processed++
+
+		docType := assetTypeOf(kv.Value)
+		if docType == "" {
+			report.Skipped = append(report.Skipped, kv.Key)
+			continue
+		}
+		if docType == DocTypeDocument {
+			moved, err := s.privatizeDocument(ctx, kv.Key, kv.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to migrate document %s: %w", kv.Key, err)
+			}
+			if moved {
+				report.Migrated["privatizedDocument"]++
+				continue
+			}
+		}
+		if err := putAssetState(ctx, docType, kv.Key, kv.Value); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s %s: %w", docType, kv.Key, err)
+		}
+		report.Migrated[docType]++
 	}
-	ar.ApprovalChain = append(ar.ApprovalChain, step)
 
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
+	if startKey == "" {
+		docIter, err := ctx.GetStub().GetStateByPartialCompositeKey(DocTypeDocument, []string{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan public documents: %w", err)
+		}
+		defer docIter.Close()
+		for docIter.HasNext() {
+			kv, err := docIter.Next()
+			if err != nil {
+				return nil, fmt.Errorf("failed to iterate public documents: %w", err)
+			}
+			moved, err := s.privatizeDocument(ctx, kv.Key, kv.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to migrate document %s: %w", kv.Key, err)
+			}
+			if moved {
+				report.Migrated["privatizedDocument"]++
+			}
+		}
+
+		privateIter, err := ctx.GetStub().GetPrivateDataByRange(studentPrivateCollection, "", "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan private documents: %w", err)
+		}
+		defer privateIter.Close()
+		for privateIter.HasNext() {
+			kv, err := privateIter.Next()
+			if err != nil {
+				return nil, fmt.Errorf("failed to iterate private documents: %w", err)
+			}
+			if assetTypeOf(kv.Value) != DocTypeDocument {
+				continue
+			}
+			if err := putPrivateAssetState(ctx, studentPrivateCollection, DocTypeDocument, kv.Key, kv.Value); err != nil {
+				return nil, fmt.Errorf("failed to migrate private document %s: %w", kv.Key, err)
+			}
+			report.Migrated["privateDocument"]++
+		}
 	}
+	report.Done = report.NextKey == ""
 
-	// Emit event
 	eventPayload := map[string]interface{}{
-		"recordId":    recordID,
-		"status":      RecordSubmitted,
-		"submittedBy": clientID,
-		"timestamp":   now.Format("2006-01-02T15:04:05Z07:00"),
+		"startKey": startKey,
+		"migrated": report.Migrated,
+		"nextKey":  report.NextKey,
+		"txId":     report.TxID,
 	}
 	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordSubmittedForApproval", eventJSON)
+	_ = ctx.GetStub().SetEvent("AssetKeysMigrated", eventJSON)
 
-	return nil
+	return report, nil
 }
 
-// FacultyApprove records the faculty's approval of an academic record
-func (s *SmartContract) FacultyApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
-	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+// ============================================================
+// RICH QUERIES
+// ============================================================
+
+// richQueryAsset describes an asset type that can be searched with a CouchDB selector.
+// Only the listed fields may be filtered on, and only fields with a packaged index
+// (META-INF/statedb/couchdb/indexes) may be sorted on.
+type richQueryAsset struct {
+	docType    string
+	required   []string          // fields that identify a document as this asset type
+	fields     map[string]string // filterable field -> "string", "number" or "bool"
+	sortIndex  map[string]string // sortable field -> index name, also used as the design document name
+	owner      string            // field holding the student's roll number
+	department string            // field holding the owning department, "" if the asset has none
+}
+
+var richQueryAssets = map[string]richQueryAsset{
+	"student": {
+		docType:  DocTypeStudent,
+		required: []string{"rollNumber", "enrollmentYear"},
+		fields: map[string]string{
+			"rollNumber": "string", "name": "string", "department": "string", "enrollmentYear": "number",
+			"admissionCategory": "string", "status": "string", "currentCGPA": "number", "totalCreditsEarned": "number",
+		},
+		sortIndex: map[string]string{
+			"rollNumber": "indexStudentRollNumber", "name": "indexStudentName",
+			"enrollmentYear": "indexStudentEnrollmentYear", "currentCGPA": "indexStudentCGPA",
+		},
+		owner:      "rollNumber",
+		department: "department",
+	},
+	"record": {
+		docType:  DocTypeRecord,
+		required: []string{"recordId", "courses"},
+		fields: map[string]string{
+			"studentId": "string", "department": "string", "semester": "number", "academicYear": "string",
+			"status": "string", "sgpa": "number", "cgpa": "number", "totalCredits": "number",
+		},
+		sortIndex: map[string]string{
+			"semester": "indexRecordSemester", "sgpa": "indexRecordSGPA", "timestamp": "indexRecordTimestamp",
+		},
+		owner:      "studentId",
+		department: "department",
+	},
+	"certificate": {
+		docType:  DocTypeCertificate,
+		required: []string{"certificateId", "pdfHash"},
+		fields: map[string]string{
+			"studentId": "string", "type": "string", "issueDate": "string", "verified": "bool",
+			"revoked": "bool", "degreeAwarded": "string",
+		},
+		sortIndex: map[string]string{
+			"issueDate": "indexCertificateIssueDate",
+		},
+		owner: "studentId",
+	},
+}
+
+// richQueryOperators are the selector operators a filter may use
+var richQueryOperators = map[string]bool{
+	"$eq": true, "$ne": true, "$gt": true, "$gte": true, "$lt": true, "$lte": true, "$in": true,
+}
+
+// validRichQueryValue reports whether a decoded JSON value has the field's kind
+func validRichQueryValue(kind string, value interface{}) bool {
+	switch kind {
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "bool":
+		_, ok := value.(bool)
+		return ok
+	default:
+		_, ok := value.(string)
+		return ok
 	}
+}
 
-	if rec.Status != RecordSubmitted {
-		return fmt.Errorf("record must be in SUBMITTED status for faculty approval, current: %s", rec.Status)
+// buildRichQuerySelector validates filtersJSON against the asset's fields and returns a
+// CouchDB selector that also identifies the asset type and limits the caller to its own data.
+// A filter is an object of field to value (equality) or to {"$op": value} with the operators
+// in richQueryOperators, e.g. {"department":"CSE","enrollmentYear":2022,"currentCGPA":{"$gte":8.5}}.
+func buildRichQuerySelector(ctx contractapi.TransactionContextInterface, asset richQueryAsset, filtersJSON string) (map[string]interface{}, error) {
+	filters := map[string]interface{}{}
+	if strings.TrimSpace(filtersJSON) != "" {
+		if err := json.Unmarshal([]byte(filtersJSON), &filters); err != nil {
+			return nil, fmt.Errorf("invalid filters JSON: %w", err)
+		}
 	}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+	selector := map[string]interface{}{}
+	for _, field := range asset.required {
+		selector[field] = map[string]interface{}{"$exists": true}
+	}
 
-	if err := s.updateRecordStatus(ctx, recordID, RecordFacultyApproved); err != nil {
-		return err
+	for field, value := range filters {
+		kind, ok := asset.fields[field]
+		if !ok {
+			return nil, fmt.Errorf("field %s cannot be used as a filter", field)
+		}
+		conditions, isObject := value.(map[string]interface{})
+		if !isObject {
+			conditions = map[string]interface{}{"$eq": value}
+		}
+		if len(conditions) == 0 {
+			return nil, fmt.Errorf("filter on %s has no condition", field)
+		}
+		for op, operand := range conditions {
+			if !richQueryOperators[op] {
+				return nil, fmt.Errorf("operator %s is not allowed in filters", op)
+			}
+			if op == "$in" {
+				values, ok := operand.([]interface{})
+				if !ok || len(values) == 0 || len(values) > 50 {
+					return nil, fmt.Errorf("$in on %s needs a list of 1 to 50 values", field)
+				}
+				for _, v := range values {
+					if !validRichQueryValue(kind, v) {
+						return nil, fmt.Errorf("filter on %s expects %s values", field, kind)
+					}
+				}
+				continue
+			}
+			if !validRichQueryValue(kind, operand) {
+				return nil, fmt.Errorf("filter on %s expects a %s value", field, kind)
+			}
+			if kind == "bool" && op != "$eq" && op != "$ne" {
+				return nil, fmt.Errorf("filter on %s only supports $eq and $ne", field)
+			}
+		}
+		selector[field] = conditions
 	}
 
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	// Scope the query to the caller, overriding any filter on the scoping field
+	caller, err := getCaller(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	ar.CurrentStatus = RecordFacultyApproved
-	ar.UpdatedAt = now
-	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
-		Role:       RoleFaculty,
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    comment,
-		TxID:       ctx.GetStub().GetTxID(),
-	})
-
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
+	switch {
+	case caller.isStudent():
+		selector[asset.owner] = map[string]interface{}{"$eq": caller.RollNumber}
+	case caller.MSPID == DepartmentsMSP:
+		if asset.department == "" {
+			return nil, fmt.Errorf("department callers cannot run this query")
+		}
+		department, found, err := ctx.GetClientIdentity().GetAttributeValue("department")
+		if err != nil || !found || department == "" {
+			return nil, fmt.Errorf("department access check failed: missing department attribute")
+		}
+		selector[asset.department] = map[string]interface{}{"$eq": department}
 	}
 
-	eventPayload := map[string]interface{}{"recordId": recordID, "role": RoleFaculty, "approvedBy": clientID, "timestamp": now}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordFacultyApproved", eventJSON)
-
-	return nil
+	return selector, nil
 }
 
-// HODApprove records the HOD's approval
-func (s *SmartContract) HODApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
-	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+// compareRichQueryValues orders two decoded JSON scalars of the same kind
+func compareRichQueryValues(a, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		if av < bv {
+			return -1, true
+		} else if av > bv {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av, bv), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		if av == bv {
+			return 0, true
+		}
+		return 1, true
 	}
+	return 0, false
+}
 
-	if rec.Status != RecordFacultyApproved {
-		return fmt.Errorf("record must be FACULTY_APPROVED before HOD approval, current: %s", rec.Status)
+// matchesRichQuerySelector evaluates a selector built by buildRichQuerySelector against a
+// document, for peers whose state database cannot run rich queries
+func matchesRichQuerySelector(selector map[string]interface{}, doc map[string]interface{}) bool {
+	for field, clause := range selector {
+		conditions, _ := clause.(map[string]interface{})
+		value, present := doc[field]
+		for op, operand := range conditions {
+			if op == "$exists" {
+				if present != operand.(bool) {
+					return false
+				}
+				continue
+			}
+			if !present {
+				return false
+			}
+			if op == "$in" {
+				found := false
+				for _, candidate := range operand.([]interface{}) {
+					if cmp, ok := compareRichQueryValues(value, candidate); ok && cmp == 0 {
+						found = true
+						break
+					}
+				}
+				if !found {
+					return false
+				}
+				continue
+			}
+			cmp, ok := compareRichQueryValues(value, operand)
+			if !ok {
+				return false
+			}
+			switch op {
+			case "$eq":
+				ok = cmp == 0
+			case "$ne":
+				ok = cmp != 0
+			case "$gt":
+				ok = cmp > 0
+			case "$gte":
+				ok = cmp >= 0
+			case "$lt":
+				ok = cmp < 0
+			case "$lte":
+				ok = cmp <= 0
+			}
+			if !ok {
+				return false
+			}
+		}
 	}
+	return true
+}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+// runRichQuery pages through documents matching the selector and returns their raw JSON.
+// Peers on LevelDB cannot run rich queries, so on those the same selector is evaluated over
+// a scan of the asset type and the bookmark is an offset into the sorted matches.
+func runRichQuery(ctx contractapi.TransactionContextInterface, asset richQueryAsset, selector map[string]interface{},
+	sortField, sortOrder, bookmark string, pageSize int) ([][]byte, string, bool, error) {
 
-	if err := s.updateRecordStatus(ctx, recordID, RecordHODApproved); err != nil {
-		return err
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 50
+	}
+	sortOrder = strings.ToLower(sortOrder)
+	if sortOrder == "" {
+		sortOrder = "asc"
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		return nil, "", false, fmt.Errorf("sortOrder must be asc or desc")
 	}
 
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	query := map[string]interface{}{"selector": selector}
+	if sortField != "" {
+		index, ok := asset.sortIndex[sortField]
+		if !ok {
+			return nil, "", false, fmt.Errorf("results cannot be sorted by %s", sortField)
+		}
+		// CouchDB only sorts on an index whose fields appear in the selector
+		if _, filtered := selector[sortField]; !filtered {
+			selector[sortField] = map[string]interface{}{"$exists": true}
+		}
+		query["sort"] = []map[string]string{{sortField: sortOrder}}
+		query["use_index"] = []string{"_design/" + index + "Doc", index}
+	}
+	queryJSON, err := json.Marshal(query)
 	if err != nil {
-		return err
+		return nil, "", false, fmt.Errorf("failed to marshal query: %w", err)
 	}
-	ar.CurrentStatus = RecordHODApproved
-	ar.UpdatedAt = now
-	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
-		Role:       RoleHOD,
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    comment,
-		TxID:       ctx.GetStub().GetTxID(),
-	})
 
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
+	iter, metadata, err := ctx.GetStub().GetQueryResultWithPagination(string(queryJSON), int32(pageSize), bookmark)
+	if err == nil {
+		defer iter.Close()
+		var results [][]byte
+		for iter.HasNext() {
+			kv, err := iter.Next()
+			if err != nil {
+				return nil, "", false, fmt.Errorf("failed to iterate query results: %v", err)
+			}
+			results = append(results, kv.Value)
+		}
+		return results, metadata.Bookmark, int(metadata.FetchedRecordsCount) == pageSize, nil
+	}
+	if !strings.Contains(strings.ToLower(err.Error()), "not supported") {
+		return nil, "", false, fmt.Errorf("failed to run rich query: %w", err)
 	}
 
-	eventPayload := map[string]interface{}{"recordId": recordID, "role": RoleHOD, "approvedBy": clientID}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordHODApproved", eventJSON)
-
-	return nil
-}
+	// LevelDB fallback
+	offset := 0
+	if bookmark != "" {
+		if _, err := fmt.Sscanf(bookmark, "%d", &offset); err != nil || offset < 0 {
+			return nil, "", false, fmt.Errorf("invalid bookmark %q", bookmark)
+		}
+	}
 
-// DACApprove is the final approval step — validates compliance, signs off, and finalizes record
-func (s *SmartContract) DACApprove(ctx contractapi.TransactionContextInterface, recordID, memberRole, comment string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
+	type match struct {
+		key   string
+		doc   map[string]interface{}
+		value []byte
 	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+	var matches []match
+	err = scanAssets(ctx, []string{asset.docType}, func(_, key string, value []byte) error {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(value, &doc); err != nil {
+			return nil
+		}
+		if matchesRichQuerySelector(selector, doc) {
+			matches = append(matches, match{key: key, doc: doc, value: value})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, "", false, err
 	}
 
-	if rec.Status != RecordDeanApproved {
-		return fmt.Errorf("record must be DEAN_APPROVED before DAC finalization, current: %s", rec.Status)
+	sort.SliceStable(matches, func(i, j int) bool {
+		if sortField != "" {
+			if cmp, ok := compareRichQueryValues(matches[i].doc[sortField], matches[j].doc[sortField]); ok && cmp != 0 {
+				if sortOrder == "desc" {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+		}
+		return matches[i].key < matches[j].key
+	})
+
+	var results [][]byte
+	for i := offset; i < len(matches) && i < offset+pageSize; i++ {
+		results = append(results, matches[i].value)
+	}
+	hasMore := offset+pageSize < len(matches)
+	nextBookmark := ""
+	if hasMore {
+		nextBookmark = fmt.Sprintf("%d", offset+pageSize)
 	}
+	return results, nextBookmark, hasMore, nil
+}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+// QueryStudents searches students with validated filters, e.g. CGPA of at least 8.5 in CSE,
+// batch 2022: {"department":"CSE","enrollmentYear":2022,"currentCGPA":{"$gte":8.5}}.
+// sortField must be one of the indexed fields; sortOrder is asc (default) or desc.
+func (s *SmartContract) QueryStudents(ctx contractapi.TransactionContextInterface,
+	filtersJSON, sortField, sortOrder, bookmark string, pageSize int) (*StudentPage, error) {
 
-	// Calculate and update CGPA since this is the final step
-	newCGPA, totalCredits, err := s.calculateCGPAIncludingCurrent(ctx, rec.StudentID, rec.Semester, rec.SGPA, rec.TotalCredits)
+	asset := richQueryAssets["student"]
+	selector, err := buildRichQuerySelector(ctx, asset, filtersJSON)
 	if err != nil {
-		return fmt.Errorf("failed to calculate CGPA: %w", err)
+		return nil, err
 	}
-	rec.CGPA = newCGPA
-
-	// Update student overall profile
-	student, err := s.GetStudent(ctx, rec.StudentID)
-	if err == nil {
-		student.CurrentCGPA = newCGPA
-		student.TotalCreditsEarned = totalCredits
-		studentJSON, _ := json.Marshal(student)
-		ctx.GetStub().PutState(student.RollNumber, studentJSON)
+	results, nextBookmark, hasMore, err := runRichQuery(ctx, asset, selector, sortField, sortOrder, bookmark, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.updateRecordStatus(ctx, recordID, RecordFinalized); err != nil {
-		return err
+	students := []*Student{}
+	for _, value := range results {
+		var student Student
+		if err := json.Unmarshal(value, &student); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal student data: %w", err)
+		}
+		students = append(students, &student)
 	}
 
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	return &StudentPage{
+		Records:     students,
+		Bookmark:    nextBookmark,
+		RecordCount: len(students),
+		HasMore:     hasMore,
+	}, nil
+}
+
+// recordGradeFields are the record query fields that unreleased records hold in a grades
+// collection. Their public stubs carry zero values in these fields.
+var recordGradeFields = []string{"sgpa", "cgpa", "totalCredits"}
+
+// QueryRecords searches academic records with validated filters. Grades of unreleased
+// records are private, so only released records match filters on sgpa, cgpa or totalCredits,
+// or appear in results sorted by sgpa.
+func (s *SmartContract) QueryRecords(ctx contractapi.TransactionContextInterface,
+	filtersJSON, sortField, sortOrder, bookmark string, pageSize int) (*RecordPage, error) {
+
+	asset := richQueryAssets["record"]
+	selector, err := buildRichQuerySelector(ctx, asset, filtersJSON)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	ar.CurrentStatus = RecordFinalized
-	ar.UpdatedAt = now
-	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
-		Role:       RoleDAC,
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    comment,
-		TxID:       ctx.GetStub().GetTxID(),
-	})
-
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
+	// Leave out stubs, whose zero grades would otherwise match the filter or sort as 0
+	for _, field := range recordGradeFields {
+		if _, filtered := selector[field]; filtered || sortField == field {
+			selector["gradesCollection"] = map[string]interface{}{"$exists": false}
+			break
+		}
+	}
+	results, nextBookmark, hasMore, err := runRichQuery(ctx, asset, selector, sortField, sortOrder, bookmark, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	// Make the record FINALIZED / APPROVED structurally
-	rec.Status = RecordFinalized
-	rec.ApprovedBy = clientID
-	rec.Timestamp = now
-	updatedJSON, _ := json.Marshal(rec)
-	ctx.GetStub().PutState(recordID, updatedJSON)
-
-	// Emit Finalized event
-	eventPayload := map[string]interface{}{
-		"recordId":   recordID,
-		"studentId":  rec.StudentID,
-		"semester":   rec.Semester,
-		"sgpa":       rec.SGPA,
-		"cgpa":       newCGPA,
-		"approvedBy": clientID,
-		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+	records := []*AcademicRecord{}
+	for _, value := range results {
+		var record AcademicRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal record: %w", err)
+		}
+		records = append(records, &record)
 	}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordFinalized", eventJSON)
 
-	return nil
+	return &RecordPage{
+		Records:     records,
+		Bookmark:    nextBookmark,
+		RecordCount: len(records),
+		HasMore:     hasMore,
+	}, nil
 }
 
-// ExamSectionApprove records Exam Section approval
-func (s *SmartContract) ExamSectionApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
+// QueryCertificates searches certificates with validated filters
+func (s *SmartContract) QueryCertificates(ctx contractapi.TransactionContextInterface,
+	filtersJSON, sortField, sortOrder, bookmark string, pageSize int) (*CertificatePage, error) {
+
+	asset := richQueryAssets["certificate"]
+	selector, err := buildRichQuerySelector(ctx, asset, filtersJSON)
+	if err != nil {
+		return nil, err
 	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+	results, nextBookmark, hasMore, err := runRichQuery(ctx, asset, selector, sortField, sortOrder, bookmark, pageSize)
+	if err != nil {
+		return nil, err
 	}
 
-	if rec.Status != RecordHODApproved {
-		return fmt.Errorf("record must be HOD_APPROVED before Exam Section locking, current: %s", rec.Status)
+	certificates := []*Certificate{}
+	for _, value := range results {
+		var certificate Certificate
+		if err := json.Unmarshal(value, &certificate); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal certificate: %w", err)
+		}
+		certificates = append(certificates, &certificate)
 	}
 
-	// Access Control: Only NITWarangalMSP (admin/exam section)
-	if err := checkMSPAccess(ctx, NITWarangalMSP); err != nil {
-		return err
-	}
+	return &CertificatePage{
+		Records:     certificates,
+		Bookmark:    nextBookmark,
+		RecordCount: len(certificates),
+		HasMore:     hasMore,
+	}, nil
+}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+// ============================================================
+// ADMISSION CATEGORIES
+// ============================================================
 
-	if err := s.updateRecordStatus(ctx, recordID, RecordESLocked); err != nil {
-		return err
-	}
+// defaultAdmissionCategories apply until SetAdmissionCategories configures a list
+var defaultAdmissionCategories = []string{"GEN", "OBC-NCL", "SC", "ST", "EWS", "PwD", "SUPERNUMERARY", "FOREIGN_NATIONAL"}
 
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+// readAdmissionCategories returns the configured admission categories
+func readAdmissionCategories(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	categoriesJSON, err := ctx.GetStub().GetState(admissionCategoriesKey)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read admission categories: %w", err)
 	}
-	ar.CurrentStatus = RecordESLocked
-	ar.UpdatedAt = now
-	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
-		Role:       RoleExamSection,
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    comment,
-		TxID:       ctx.GetStub().GetTxID(),
-	})
-
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
+	if categoriesJSON == nil {
+		return defaultAdmissionCategories, nil
 	}
 
-	eventPayload := map[string]interface{}{"recordId": recordID, "role": RoleExamSection, "approvedBy": clientID}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordExamLocked", eventJSON)
-
-	return nil
+	var categories []string
+	if err := json.Unmarshal(categoriesJSON, &categories); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal admission categories: %w", err)
+	}
+	return categories, nil
 }
 
-// DeanAcademicApprove records Dean approval, but no longer finalizes the record.
-func (s *SmartContract) DeanAcademicApprove(ctx contractapi.TransactionContextInterface, recordID, comment string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
+// resolveAdmissionCategory matches a category case-insensitively against the configured list
+// and returns it as configured
+func resolveAdmissionCategory(ctx contractapi.TransactionContextInterface, category string) (string, error) {
+	categories, err := readAdmissionCategories(ctx)
+	if err != nil {
+		return "", err
 	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+	category = strings.TrimSpace(category)
+	for _, valid := range categories {
+		if strings.EqualFold(category, valid) {
+			return valid, nil
+		}
 	}
+	return "", fmt.Errorf("invalid admission category %q, must be one of %s", category, strings.Join(categories, ", "))
+}
 
-	if rec.Status != RecordESLocked {
-		return fmt.Errorf("record must be EXAM_LOCKED before Dean Academic approval, current: %s", rec.Status)
+// SetAdmissionCategories replaces the list of admission categories. Students already admitted
+// under a category that is dropped keep it and still appear in reports.
+func (s *SmartContract) SetAdmissionCategories(ctx contractapi.TransactionContextInterface, categoriesJSON string) error {
+	var categories []string
+	if err := json.Unmarshal([]byte(categoriesJSON), &categories); err != nil {
+		return fmt.Errorf("invalid categories JSON: %w", err)
 	}
-
-	// Access Control: Only NITWarangalMSP
-	if err := checkMSPAccess(ctx, NITWarangalMSP); err != nil {
-		return err
+	if len(categories) == 0 {
+		return fmt.Errorf("at least one admission category is required")
 	}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
-
-	if err := s.updateRecordStatus(ctx, recordID, RecordDeanApproved); err != nil {
-		return err
+	seen := map[string]bool{}
+	for i, category := range categories {
+		category = strings.TrimSpace(category)
+		if category == "" || len(category) > 30 {
+			return fmt.Errorf("admission category must be between 1 and 30 characters")
+		}
+		if seen[strings.ToUpper(category)] {
+			return fmt.Errorf("duplicate admission category %s", category)
+		}
+		seen[strings.ToUpper(category)] = true
+		categories[i] = category
 	}
 
-	// Record dean approval
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
+	stored, err := json.Marshal(categories)
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to marshal admission categories: %w", err)
 	}
-	ar.CurrentStatus = RecordDeanApproved
-	ar.UpdatedAt = now
-	ar.ApprovalChain = append(ar.ApprovalChain, ApprovalStep{
-		Role:       RoleDeanAcademic,
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    comment,
-		TxID:       ctx.GetStub().GetTxID(),
-	})
-
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
+	if err := ctx.GetStub().PutState(admissionCategoriesKey, stored); err != nil {
+		return fmt.Errorf("failed to put admission categories: %w", err)
 	}
 
-	// Emit event
+	clientID, _ := ctx.GetClientIdentity().GetID()
 	eventPayload := map[string]interface{}{
-		"recordId":   recordID,
-		"role":       RoleDeanAcademic,
-		"approvedBy": clientID,
-		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+		"categories": categories,
+		"modifiedBy": clientID,
 	}
 	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordDeanApproved", eventJSON)
+	_ = ctx.GetStub().SetEvent("AdmissionCategoriesUpdated", eventJSON)
 
 	return nil
 }
 
-// RejectRecord allows any approver to reject and send back with a reason
-func (s *SmartContract) RejectRecord(ctx contractapi.TransactionContextInterface, recordID, reason string) error {
-	recJSON, err := ctx.GetStub().GetState(recordID)
-	if err != nil || recJSON == nil {
-		return fmt.Errorf("record %s not found", recordID)
-	}
-	var rec AcademicRecord
-	if err := json.Unmarshal(recJSON, &rec); err != nil {
-		return err
+// GetAdmissionCategories returns the admission categories CreateStudent accepts
+func (s *SmartContract) GetAdmissionCategories(ctx contractapi.TransactionContextInterface) ([]string, error) {
+	return readAdmissionCategories(ctx)
+}
+
+// CategoryReportRow is the number of students of one admission category in a department and
+// enrollment year. AverageCGPA is over the students that have a CGPA yet, i.e. released
+// records with GPA credits; a CGPA of 0 from failed courses is counted.
+type CategoryReportRow struct {
+	Department        string  `json:"department"`
+	EnrollmentYear    int     `json:"enrollmentYear"`
+	AdmissionCategory string  `json:"admissionCategory"`
+	Count             int     `json:"count"`
+	GradedCount       int     `json:"gradedCount"`
+	AverageCGPA       float64 `json:"averageCGPA"`
+}
+
+// GetAdmissionCategoryReport aggregates students by department, enrollment year and admission
+// category for statutory reporting. Either filter may be empty (0 for the year) to cover all;
+// department callers must name their own department.
+func (s *SmartContract) GetAdmissionCategoryReport(ctx contractapi.TransactionContextInterface,
+	department string, enrollmentYear int) ([]CategoryReportRow, error) {
+
+	department = strings.ToUpper(department)
+	if err := checkDepartmentAccess(ctx, department); err != nil {
+		return nil, err
 	}
 
-	// Cannot reject if already fully approved or already rejected
-	if rec.Status == RecordApproved || rec.Status == RecordRejected {
-		return fmt.Errorf("cannot reject record with status %s", rec.Status)
+	// Walk the narrowest index the filters allow
+	index, attributes := StudentDeptKey, []string{}
+	if department != "" {
+		attributes = []string{department}
+	} else if enrollmentYear > 0 {
+		index, attributes = StudentYearKey, []string{fmt.Sprintf("%d", enrollmentYear)}
+	}
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(index, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query students: %w", err)
 	}
+	defer iter.Close()
 
-	if reason == "" {
-		return fmt.Errorf("rejection reason is required")
+	type groupKey struct {
+		department string
+		year       int
+		category   string
 	}
+	groups := map[groupKey]*CategoryReportRow{}
+	cgpaTotals := map[groupKey]int64{} // hundredths
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		student, err := s.readStudent(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		if enrollmentYear > 0 && student.EnrollmentYear != enrollmentYear {
+			continue
+		}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+		key := groupKey{student.Department, student.EnrollmentYear, student.AdmissionCategory}
+		row, ok := groups[key]
+		if !ok {
+			row = &CategoryReportRow{Department: key.department, EnrollmentYear: key.year, AdmissionCategory: key.category}
+			groups[key] = row
+		}
+		row.Count++
 
-	// Reset back to DRAFT for correction
-	if err := s.updateRecordStatus(ctx, recordID, RecordDraft); err != nil {
-		return err
+		released, err := s.releasedRecords(ctx, student.RollNumber)
+		if err != nil {
+			return nil, err
+		}
+		if _, gpaCredits, _ := cumulativeGPA(gpaRecords(released)); gpaCredits > 0 {
+			row.GradedCount++
+			cgpaTotals[key] += toHundredths(student.CurrentCGPA)
+		}
 	}
 
-	// Update rejection note on record
-	rec.Status = RecordDraft
-	rec.RejectionNote = reason
-	updatedJSON, _ := json.Marshal(rec)
-	ctx.GetStub().PutState(recordID, updatedJSON)
-
-	ar, err := s.getOrCreateApprovalRecord(ctx, recordID)
-	if err != nil {
-		return err
+	report := make([]CategoryReportRow, 0, len(groups))
+	for key, row := range groups {
+		if row.GradedCount > 0 {
+			row.AverageCGPA = fromHundredths(gpaQuotient(cgpaTotals[key], int64(row.GradedCount)))
+		}
+		report = append(report, *row)
 	}
-	ar.CurrentStatus = RecordDraft
-	ar.UpdatedAt = now
-	ar.Rejections = append(ar.Rejections, ApprovalStep{
-		Role:       "any",
-		ApprovedBy: clientID,
-		Timestamp:  now,
-		Comment:    reason,
-		TxID:       ctx.GetStub().GetTxID(),
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Department != report[j].Department {
+			return report[i].Department < report[j].Department
+		}
+		if report[i].EnrollmentYear != report[j].EnrollmentYear {
+			return report[i].EnrollmentYear < report[j].EnrollmentYear
+		}
+		return report[i].AdmissionCategory < report[j].AdmissionCategory
 	})
-	// Reset approval chain on rejection
-	ar.ApprovalChain = []ApprovalStep{}
+	return report, nil
+}
 
-	if err := s.saveApprovalRecord(ctx, ar); err != nil {
-		return err
-	}
+// ============================================================
+// GRADING SCHEMES
+// ============================================================
 
-	eventPayload := map[string]interface{}{"recordId": recordID, "rejectedBy": clientID, "reason": reason}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("RecordRejected", eventJSON)
+// GradingSchemeProgramKey indexes grading schemes: gradingscheme~program~{Program}~{SchemeID}
+const GradingSchemeProgramKey = "gradingscheme~program"
 
-	return nil
+// AllPrograms is the program of a grading scheme that applies to every program
+const AllPrograms = "*"
+
+// DefaultGradingSchemeID identifies the built-in 10-point scheme. It applies when no stored
+// scheme matches and to records graded before schemes were configurable.
+const DefaultGradingSchemeID = "NITW-10POINT"
+
+// GradeDefinition describes one grade letter of a scheme. Grades that do not count toward the
+// GPA (audit or pass/fail grades) are left out of the SGPA, points and credits alike.
+type GradeDefinition struct {
+	Grade               string  `json:"grade"`
+	Points              float64 `json:"points"`
+	Passing             bool    `json:"passing"`
+	CountsTowardCredits bool    `json:"countsTowardCredits"`
+	CountsTowardGPA     bool    `json:"countsTowardGPA"`
 }
 
-// ============================================================
-// DOCUMENT UPLOAD & HASH VERIFICATION
-// ============================================================
+// GradingScheme is the grade-point table for a program from an academic year onwards.
+// Schemes are immutable once created so that records keep computing with the scheme they
+// were graded under; a change of scheme is a new scheme with a later EffectiveFrom.
+type GradingScheme struct {
+	DocType       string            `json:"docType"`
+	SchemeID      string            `json:"schemeId"`
+	Program       string            `json:"program"`       // department code, or * for every program
+	EffectiveFrom string            `json:"effectiveFrom"` // academic year, e.g. "2024-25"
+	Grades        []GradeDefinition `json:"grades"`
+	CreatedBy     string            `json:"createdBy"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
 
-// UploadDocument stores a document hash on the blockchain
-func (s *SmartContract) UploadDocument(ctx contractapi.TransactionContextInterface,
-	docID, studentID, docType, sha256Hash, fileName, academicYear string, semester int) error {
+// defaultGradingScheme is the 10-point scale used before grading schemes were configurable
+var defaultGradingScheme = GradingScheme{
+	DocType:       DocTypeGradingScheme,
+	SchemeID:      DefaultGradingSchemeID,
+	Program:       AllPrograms,
+	EffectiveFrom: "1950",
+	Grades: []GradeDefinition{
+		{Grade: GradeS, Points: 10, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+		{Grade: GradeA, Points: 9, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+		{Grade: GradeB, Points: 8, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+		{Grade: GradeC, Points: 7, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+		{Grade: GradeD, Points: 6, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+		{Grade: GradeP, Points: 5, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+		{Grade: GradeU, Points: 0, Passing: false, CountsTowardCredits: false, CountsTowardGPA: true},
+		{Grade: GradeR, Points: 0, Passing: false, CountsTowardCredits: false, CountsTowardGPA: true},
+	},
+}
 
-	// Verify student exists
-	exists, err := s.StudentExists(ctx, studentID)
+// gradeSchemeIDPattern restricts scheme IDs to letters, digits, '-' and '_'
+var gradeSchemeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,40}$`)
+
+// lookup returns the definition of a grade letter in the scheme
+func (scheme *GradingScheme) lookup(grade string) (*GradeDefinition, bool) {
+	for i := range scheme.Grades {
+		if scheme.Grades[i].Grade == grade {
+			return &scheme.Grades[i], true
+		}
+	}
+	return nil, false
+}
+
+// academicStartYear returns the first calendar year of an academic year label
+func academicStartYear(academicYear string) int {
+	year := 0
+	fmt.Sscanf(academicYear, "%4d", &year)
+	return year
+}
+
+// readGradingScheme loads a grading scheme by ID; the built-in scheme needs no ledger state
+func readGradingScheme(ctx contractapi.TransactionContextInterface, schemeID string) (*GradingScheme, error) {
+	if schemeID == "" || schemeID == DefaultGradingSchemeID {
+		scheme := defaultGradingScheme
+		return &scheme, nil
+	}
+	schemeJSON, err := getAssetState(ctx, DocTypeGradingScheme, schemeID)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to read grading scheme: %w", err)
 	}
-	if !exists {
-		return fmt.Errorf("student %s does not exist", studentID)
+	if schemeJSON == nil {
+		return nil, fmt.Errorf("grading scheme %s does not exist", schemeID)
 	}
+	var scheme GradingScheme
+	if err := json.Unmarshal(schemeJSON, &scheme); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal grading scheme: %w", err)
+	}
+	return &scheme, nil
+}
 
-	// Check if document with same hash already exists (deduplication)
-	existingHashKey, _ := ctx.GetStub().CreateCompositeKey(DocumentHashKey, []string{sha256Hash})
-	existing, _ := ctx.GetStub().GetState(existingHashKey)
-	if existing != nil {
-		return fmt.Errorf("document with hash %s already exists on the ledger", sha256Hash)
+// readGradingSchemes returns the stored schemes of one program (AllPrograms for the schemes
+// that apply everywhere), or of every program when program is empty
+func readGradingSchemes(ctx contractapi.TransactionContextInterface, program string) ([]*GradingScheme, error) {
+	attributes := []string{}
+	if program != "" {
+		attributes = []string{program}
+	}
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(GradingSchemeProgramKey, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query grading schemes: %w", err)
 	}
+	defer iter.Close()
 
-	// Validate document type
-	validDocTypes := map[string]bool{
-		"GRADE_SHEET": true, "DEGREE_CERT": true, "TRANSCRIPT": true,
-		"AADHAAR": true, "PHOTO": true, "MARKSHEET": true, "OTHER": true,
+	schemes := []*GradingScheme{}
+	for iter.HasNext() {
+		kv, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		scheme, err := readGradingScheme(ctx, parts[1])
+		if err != nil {
+			continue
+		}
+		schemes = append(schemes, scheme)
 	}
-	if !validDocTypes[docType] {
-		return fmt.Errorf("invalid document type: %s", docType)
+	return schemes, nil
+}
+
+// resolveGradingScheme picks the scheme in force for a program in an academic year: the one
+// with the latest EffectiveFrom not after that year, preferring a program's own scheme over
+// one for every program, and the built-in scheme when none applies
+func resolveGradingScheme(ctx contractapi.TransactionContextInterface, program, academicYear string) (*GradingScheme, error) {
+	program = strings.ToUpper(program)
+	year := academicStartYear(academicYear)
+
+	var best *GradingScheme
+	bestYear := 0
+	for _, candidateProgram := range []string{program, AllPrograms} {
+		schemes, err := readGradingSchemes(ctx, candidateProgram)
+		if err != nil {
+			return nil, err
+		}
+		for _, scheme := range schemes {
+			from := academicStartYear(scheme.EffectiveFrom)
+			if from > year {
+				continue
+			}
+			// Program schemes are visited first, so a scheme for every program only wins
+			// when it takes effect strictly later
+			if best == nil || from > bestYear || (from == bestYear && best.Program == scheme.Program && scheme.SchemeID > best.SchemeID) {
+				best, bestYear = scheme, from
+			}
+		}
 	}
+	if best == nil {
+		scheme := defaultGradingScheme
+		return &scheme, nil
+	}
+	return best, nil
+}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+// CreateGradingScheme stores a new grading scheme. schemeJSON carries schemeId, program
+// (department code or *), effectiveFrom (academic year) and the grades.
+func (s *SmartContract) CreateGradingScheme(ctx contractapi.TransactionContextInterface, schemeJSON string) error {
+	var scheme GradingScheme
+	if err := json.Unmarshal([]byte(schemeJSON), &scheme); err != nil {
+		return fmt.Errorf("invalid grading scheme JSON: %w", err)
+	}
 
-	doc := DocumentUpload{
-		DocID:        docID,
-		StudentID:    studentID,
-		DocType:      docType,
-		SHA256Hash:   sha256Hash,
-		FileName:     fileName,
-		Semester:     semester,
-		AcademicYear: academicYear,
-		UploadedBy:   clientID,
-		UploadedAt:   now,
-		IsVerified:   false,
+	if !gradeSchemeIDPattern.MatchString(scheme.SchemeID) {
+		return fmt.Errorf("scheme ID must be 3-40 letters, digits, '-' or '_'")
+	}
+	if scheme.SchemeID == DefaultGradingSchemeID {
+		return fmt.Errorf("scheme ID %s is reserved for the built-in scheme", DefaultGradingSchemeID)
+	}
+	scheme.Program = strings.ToUpper(strings.TrimSpace(scheme.Program))
+	if scheme.Program == "" {
+		return fmt.Errorf("program is required (use %s for every program)", AllPrograms)
+	}
+	scheme.EffectiveFrom = strings.TrimSpace(scheme.EffectiveFrom)
+	if err := validateAcademicYear(scheme.EffectiveFrom); err != nil {
+		return err
 	}
 
-	docJSON, err := json.Marshal(doc)
-	if err != nil {
-		return fmt.Errorf("failed to marshal document: %w", err)
+	if len(scheme.Grades) == 0 {
+		return fmt.Errorf("at least one grade is required")
+	}
+	seen := map[string]bool{}
+	passing := false
+	for i := range scheme.Grades {
+		grade := &scheme.Grades[i]
+		grade.Grade = strings.ToUpper(strings.TrimSpace(grade.Grade))
+		if grade.Grade == "" || len(grade.Grade) > 4 {
+			return fmt.Errorf("grade %d: letter must be 1-4 characters", i+1)
+		}
+		if seen[grade.Grade] {
+			return fmt.Errorf("duplicate grade %s", grade.Grade)
+		}
+		seen[grade.Grade] = true
+		if grade.Points < 0 || grade.Points > 10 {
+			return fmt.Errorf("grade %s: points must be between 0 and 10", grade.Grade)
+		}
+		if !grade.CountsTowardGPA {
+			grade.Points = 0
+		}
+		if grade.CountsTowardCredits && !grade.Passing {
+			return fmt.Errorf("grade %s: a failing grade cannot earn credits", grade.Grade)
+		}
+		passing = passing || grade.Passing
+	}
+	if !passing {
+		return fmt.Errorf("a grading scheme needs at least one passing grade")
 	}
 
-	// Store main document record
-	if err := ctx.GetStub().PutState(docID, docJSON); err != nil {
-		return fmt.Errorf("failed to store document: %w", err)
+	existing, err := getAssetState(ctx, DocTypeGradingScheme, scheme.SchemeID)
+	if err != nil {
+		return fmt.Errorf("failed to read grading scheme: %w", err)
+	}
+	if existing != nil {
+		return fmt.Errorf("grading scheme %s already exists; schemes cannot be changed once created", scheme.SchemeID)
 	}
 
-	// Composite key: document~student for querying by student
-	studentDocKey, _ := ctx.GetStub().CreateCompositeKey(DocumentKey, []string{studentID, docID})
-	ctx.GetStub().PutState(studentDocKey, []byte{0x00})
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return fmt.Errorf("failed to get client identity: %w", err)
+	}
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	scheme.DocType = DocTypeGradingScheme
+	scheme.CreatedBy = clientID
+	scheme.CreatedAt = time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
 
-	// Composite key: document~hash for hash-based lookup
-	ctx.GetStub().PutState(existingHashKey, []byte(docID))
+	stored, err := json.Marshal(scheme)
+	if err != nil {
+		return fmt.Errorf("failed to marshal grading scheme: %w", err)
+	}
+	if err := putAssetState(ctx, DocTypeGradingScheme, scheme.SchemeID, stored); err != nil {
+		return fmt.Errorf("failed to store grading scheme: %w", err)
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(GradingSchemeProgramKey, []string{scheme.Program, scheme.SchemeID})
+	if err != nil {
+		return fmt.Errorf("failed to create grading scheme index key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return fmt.Errorf("failed to store grading scheme index: %w", err)
+	}
 
-	// Emit event
 	eventPayload := map[string]interface{}{
-		"docId":      docID,
-		"studentId":  studentID,
-		"docType":    docType,
-		"sha256Hash": sha256Hash,
-		"uploadedBy": clientID,
-		"timestamp":  now.Format("2006-01-02T15:04:05Z07:00"),
+		"schemeId":      scheme.SchemeID,
+		"program":       scheme.Program,
+		"effectiveFrom": scheme.EffectiveFrom,
+		"grades":        len(scheme.Grades),
+		"createdBy":     clientID,
 	}
 	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("DocumentUploaded", eventJSON)
+	ctx.GetStub().SetEvent("GradingSchemeCreated", eventJSON)
 
 	return nil
 }
 
-// GetDocument retrieves a document upload record by docID
-func (s *SmartContract) GetDocument(ctx contractapi.TransactionContextInterface, docID string) (*DocumentUpload, error) {
-	docJSON, err := ctx.GetStub().GetState(docID)
+// GetGradingScheme returns a grading scheme by ID, including the built-in one
+func (s *SmartContract) GetGradingScheme(ctx contractapi.TransactionContextInterface, schemeID string) (*GradingScheme, error) {
+	return readGradingScheme(ctx, schemeID)
+}
+
+// GetGradingSchemes returns the stored schemes of a program, or of every program when program
+// is empty, ordered by program and effective year
+func (s *SmartContract) GetGradingSchemes(ctx contractapi.TransactionContextInterface, program string) ([]*GradingScheme, error) {
+	schemes, err := readGradingSchemes(ctx, strings.ToUpper(program))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read document: %w", err)
-	}
-	if docJSON == nil {
-		return nil, fmt.Errorf("document %s does not exist", docID)
+		return nil, err
 	}
+	sort.Slice(schemes, func(i, j int) bool {
+		if schemes[i].Program != schemes[j].Program {
+			return schemes[i].Program < schemes[j].Program
+		}
+		if schemes[i].EffectiveFrom != schemes[j].EffectiveFrom {
+			return schemes[i].EffectiveFrom < schemes[j].EffectiveFrom
+		}
+		return schemes[i].SchemeID < schemes[j].SchemeID
+	})
+	return schemes, nil
+}
 
-	var doc DocumentUpload
-	if err := json.Unmarshal(docJSON, &doc); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+// GetEffectiveGradingScheme returns the scheme that grades a program's records in an academic year
+func (s *SmartContract) GetEffectiveGradingScheme(ctx contractapi.TransactionContextInterface, program, academicYear string) (*GradingScheme, error) {
+	academicYear = strings.TrimSpace(academicYear)
+	if err := validateAcademicYear(academicYear); err != nil {
+		return nil, err
 	}
-	return &doc, nil
+	return resolveGradingScheme(ctx, program, academicYear)
 }
 
-// VerifyDocumentByHash checks if a document with the given hash exists on-chain
-func (s *SmartContract) VerifyDocumentByHash(ctx contractapi.TransactionContextInterface, sha256Hash string) (*DocumentUpload, error) {
-	hashKey, _ := ctx.GetStub().CreateCompositeKey(DocumentHashKey, []string{sha256Hash})
-	docIDBytes, err := ctx.GetStub().GetState(hashKey)
-	if err != nil {
-		return nil, fmt.Errorf("failed to look up hash: %w", err)
-	}
-	if docIDBytes == nil {
-		return nil, fmt.Errorf("no document found with hash %s — document may be tampered or not registered", sha256Hash)
-	}
+// ============================================================
+// RESULT ANALYTICS
+// ============================================================
 
-	return s.GetDocument(ctx, string(docIDBytes))
+// gradeOrder lists the built-in grades from best to worst; histograms always carry these, followed
+// by any other grade awarded under a configured scheme in alphabetical order
+var gradeOrder = []string{GradeS, GradeA, GradeB, GradeC, GradeD, GradeP, GradeU, GradeR}
+
+// roundTo2 rounds a non-negative value to two decimals
+func roundTo2(value float64) float64 {
+	return fromHundredths(toHundredths(value))
+}
+
+// GradeCount is the number of results with one grade
+type GradeCount struct {
+	Grade string `json:"grade"`
+	Count int    `json:"count"`
+}
+
+// CourseResultStats summarises the results of one course
+type CourseResultStats struct {
+	CourseCode     string       `json:"courseCode"`
+	CourseName     string       `json:"courseName"`
+	Appeared       int          `json:"appeared"`
+	Passed         int          `json:"passed"`
+	Failed         int          `json:"failed"`
+	PassPercentage float64      `json:"passPercentage"`
+	Grades         []GradeCount `json:"grades"`
+}
+
+// SGPABand counts records whose SGPA is at least From and below To (the top band includes 10)
+type SGPABand struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// StudentResult is one student's SGPA in the analysed semester
+type StudentResult struct {
+	StudentID string  `json:"studentId"`
+	RecordID  string  `json:"recordId"`
+	SGPA      float64 `json:"sgpa"`
+}
+
+// DepartmentResultAnalytics aggregates the finalized records of a department for one semester
+// and academic year. A student passes the semester when every course has a passing grade under
+// the record's grading scheme.
+type DepartmentResultAnalytics struct {
+	Department       string              `json:"department"`
+	Semester         int                 `json:"semester"`
+	AcademicYear     string              `json:"academicYear"`
+	RecordCount      int                 `json:"recordCount"`
+	Passed           int                 `json:"passed"`
+	Failed           int                 `json:"failed"`
+	PassPercentage   float64             `json:"passPercentage"`
+	MeanSGPA         float64             `json:"meanSGPA"`
+	MedianSGPA       float64             `json:"medianSGPA"`
+	SGPADistribution []SGPABand          `json:"sgpaDistribution"`
+	Courses          []CourseResultStats `json:"courses"`
+	TopStudents      []StudentResult     `json:"topStudents"`
 }
 
-// GetDocumentsByStudent returns all documents uploaded for a student
-func (s *SmartContract) GetDocumentsByStudent(ctx contractapi.TransactionContextInterface, studentID string) ([]*DocumentUpload, error) {
-	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(DocumentKey, []string{studentID})
+// GetDepartmentResultAnalytics computes pass percentages, per-course grade histograms, the SGPA
+// distribution and the topN students (10 by default, at most 100) over a department's FINALIZED
+// records for a semester and academic year. Every list is sorted so that all endorsing peers
+// return identical results.
+func (s *SmartContract) GetDepartmentResultAnalytics(ctx contractapi.TransactionContextInterface,
+	department string, semester int, academicYear string, topN int) (*DepartmentResultAnalytics, error) {
+
+	if err := checkDepartmentAccess(ctx, department); err != nil {
+		return nil, err
+	}
+	if err := validateSemester(semester); err != nil {
+		return nil, err
+	}
+	academicYear = strings.TrimSpace(academicYear)
+	if err := validateAcademicYear(academicYear); err != nil {
+		return nil, err
+	}
+	if topN <= 0 || topN > 100 {
+		topN = 10
+	}
+
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(RecordDeptKey, []string{department})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get documents for student %s: %w", studentID, err)
+		return nil, fmt.Errorf("failed to query records by department: %w", err)
 	}
 	defer iter.Close()
 
-	var docs []*DocumentUpload
+	analytics := &DepartmentResultAnalytics{
+		Department:   department,
+		Semester:     semester,
+		AcademicYear: academicYear,
+		Courses:      []CourseResultStats{},
+		TopStudents:  []StudentResult{},
+	}
+	courses := map[string]*CourseResultStats{}
+	gradeCounts := map[string]map[string]int{}
+	otherGrades := map[string]bool{}
+	schemes := map[string]*GradingScheme{}
+	results := []StudentResult{}
+
 	for iter.HasNext() {
 		kv, err := iter.Next()
 		if err != nil {
-			continue
+			return nil, err
 		}
-		// Extract docID from composite key
 		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
-		if err != nil || len(parts) < 2 {
+		if err != nil || len(parts) < 3 {
 			continue
 		}
-		docID := parts[1]
-
-		doc, err := s.GetDocument(ctx, docID)
+		record, err := s.readRecordState(ctx, parts[2])
 		if err != nil {
 			continue
 		}
-		docs = append(docs, doc)
+		if record.Status != RecordFinalized || record.Semester != semester || record.AcademicYear != academicYear {
+			continue
+		}
+
+		scheme, ok := schemes[record.GradingSchemeID]
+		if !ok {
+			scheme, err = readGradingScheme(ctx, record.GradingSchemeID)
+			if err != nil {
+				return nil, err
+			}
+			schemes[record.GradingSchemeID] = scheme
+		}
+
+		passed := true
+		for _, course := range record.Courses {
+			stats, ok := courses[course.CourseCode]
+			if !ok {
+				stats = &CourseResultStats{CourseCode: course.CourseCode, CourseName: course.CourseName}
+				courses[course.CourseCode] = stats
+				gradeCounts[course.CourseCode] = map[string]int{}
+			}
+			stats.Appeared++
+			gradeCounts[course.CourseCode][course.Grade]++
+			otherGrades[course.Grade] = true
+			if definition, ok := scheme.lookup(course.Grade); ok && definition.Passing {
+				stats.Passed++
+			} else {
+				stats.Failed++
+				passed = false
+			}
+		}
+		if passed {
+			analytics.Passed++
+		} else {
+			analytics.Failed++
+		}
+		results = append(results, StudentResult{StudentID: record.StudentID, RecordID: record.RecordID, SGPA: record.SGPA})
 	}
 
-	if docs == nil {
-		docs = []*DocumentUpload{}
+	analytics.RecordCount = len(results)
+	if analytics.RecordCount > 0 {
+		analytics.PassPercentage = roundTo2(float64(analytics.Passed) * 100 / float64(analytics.RecordCount))
 	}
-	return docs, nil
-}
 
-// ============================================================
-// SEMESTER REGISTRATION
-// ============================================================
+	// Highest SGPA first; ties broken by roll number and record ID so the order is total
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].SGPA != results[j].SGPA {
+			return results[i].SGPA > results[j].SGPA
+		}
+		if results[i].StudentID != results[j].StudentID {
+			return results[i].StudentID < results[j].StudentID
+		}
+		return results[i].RecordID < results[j].RecordID
+	})
 
-// RegisterForSemester registers a student for a semester
-func (s *SmartContract) RegisterForSemester(ctx contractapi.TransactionContextInterface,
-	regID, studentID, academicYear, facultyAdvisor string, semester int) error {
+	bands := []SGPABand{{From: 9, To: 10}, {From: 8, To: 9}, {From: 7, To: 8}, {From: 6, To: 7}, {From: 5, To: 6}, {From: 0, To: 5}}
+	var total int64
+	for _, result := range results {
+		total += toHundredths(result.SGPA)
+		for i := range bands {
+			if result.SGPA >= bands[i].From {
+				bands[i].Count++
+				break
+			}
+		}
+	}
+	analytics.SGPADistribution = bands
 
-	// Verify student exists
-	exists, err := s.StudentExists(ctx, studentID)
-	if err != nil {
-		return err
+	if n := len(results); n > 0 {
+		analytics.MeanSGPA = fromHundredths(gpaQuotient(total, int64(n)))
+		// results are sorted descending, so the middle element(s) give the median either way
+		if n%2 == 1 {
+			analytics.MedianSGPA = fromHundredths(toHundredths(results[n/2].SGPA))
+		} else {
+			analytics.MedianSGPA = fromHundredths(gpaQuotient(toHundredths(results[n/2-1].SGPA)+toHundredths(results[n/2].SGPA), 2))
+		}
 	}
-	if !exists {
-		return fmt.Errorf("student %s does not exist", studentID)
+
+	if len(results) > topN {
+		analytics.TopStudents = results[:topN]
+	} else {
+		analytics.TopStudents = results
 	}
 
-	if err := validateSemester(semester); err != nil {
-		return err
+	histogramGrades := append([]string{}, gradeOrder...)
+	for _, grade := range gradeOrder {
+		delete(otherGrades, grade)
+	}
+	extraGrades := make([]string, 0, len(otherGrades))
+	for grade := range otherGrades {
+		extraGrades = append(extraGrades, grade)
 	}
+	sort.Strings(extraGrades)
+	histogramGrades = append(histogramGrades, extraGrades...)
 
-	// Check if registration already exists
-	existingJSON, _ := ctx.GetStub().GetState(regID)
-	if existingJSON != nil {
-		return fmt.Errorf("registration %s already exists", regID)
+	codes := make([]string, 0, len(courses))
+	for code := range courses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		stats := courses[code]
+		stats.PassPercentage = roundTo2(float64(stats.Passed) * 100 / float64(stats.Appeared))
+		stats.Grades = make([]GradeCount, 0, len(histogramGrades))
+		for _, grade := range histogramGrades {
+			stats.Grades = append(stats.Grades, GradeCount{Grade: grade, Count: gradeCounts[code][grade]})
+		}
+		analytics.Courses = append(analytics.Courses, *stats)
 	}
 
-	clientID, _ := ctx.GetClientIdentity().GetID()
-	txTimestamp, _ := ctx.GetStub().GetTxTimestamp()
-	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+	return analytics, nil
+}
 
-	reg := SemesterRegistration{
-		RegID:          regID,
-		StudentID:      studentID,
-		Semester:       semester,
-		AcademicYear:   academicYear,
-		FacultyAdvisor: facultyAdvisor,
-		Status:         "REGISTERED",
-		RegisteredBy:   clientID,
-		RegisteredAt:   now,
-		UpdatedAt:      now,
-	}
+// ============================================================
+// MERIT LISTS
+// ============================================================
 
-	regJSON, err := json.Marshal(reg)
+// MeritListDeptKey indexes merit lists: meritlist~dept~{Department}~{Batch}~{MeritListID}
+const MeritListDeptKey = "meritlist~dept"
+
+// MeritListEntry is one ranked student. CGPA is computed with cumulativeGPA from the student's
+// released (APPROVED or FINALIZED) records up to the cutoff date; CurrentCGPA is
+// Student.CurrentCGPA when the list was generated.
+type MeritListEntry struct {
+	Rank               int     `json:"rank"`
+	RollNumber         string  `json:"rollNumber"`
+	Name               string  `json:"name"`
+	CGPA               float64 `json:"cgpa"`
+	CurrentCGPA        float64 `json:"currentCGPA"`
+	CreditsEarned      float64 `json:"creditsEarned"`
+	SemestersCompleted int     `json:"semestersCompleted"`
+	LatestSGPA         float64 `json:"latestSGPA"`
+}
+
+// MeritList is an immutable ranked snapshot of a department's batch. EntriesHash is the SHA-256
+// of the department, batch, cutoff date and entries, so a copy can be checked against the ledger.
+type MeritList struct {
+	DocType     string           `json:"docType"`
+	MeritListID string           `json:"meritListId"`
+	Department  string           `json:"department"`
+	Batch       int              `json:"batch"`
+	CutoffDate  string           `json:"cutoffDate"` // YYYY-MM-DD, inclusive
+	Entries     []MeritListEntry `json:"entries"`
+	EntriesHash string           `json:"entriesHash"`
+	GeneratedBy string           `json:"generatedBy"`
+	GeneratedAt time.Time        `json:"generatedAt"`
+	TxID        string           `json:"txId"`
+}
+
+// MeritListPage is one page of merit lists
+type MeritListPage struct {
+	Records       []*MeritList `json:"records"`
+	Bookmark      string       `json:"bookmark"`
+	RecordCount   int          `json:"recordCount"`
+	FilteredCount int          `json:"filteredCount"`
+	HasMore       bool         `json:"hasMore"`
+}
+
+// MeritListVerification is the result of checking a merit list's hash
+type MeritListVerification struct {
+	MeritListID  string `json:"meritListId"`
+	StoredHash   string `json:"storedHash"`
+	ComputedHash string `json:"computedHash"`
+	Valid        bool   `json:"valid"`
+	Message      string `json:"message"`
+}
+
+// meritListHash hashes the ranked content of a merit list
+func meritListHash(list *MeritList) (string, error) {
+	content, err := json.Marshal(struct {
+		Department string           `json:"department"`
+		Batch      int              `json:"batch"`
+		CutoffDate string           `json:"cutoffDate"`
+		Entries    []MeritListEntry `json:"entries"`
+	}{list.Department, list.Batch, list.CutoffDate, list.Entries})
 	if err != nil {
-		return fmt.Errorf("failed to marshal registration: %w", err)
+		return "", fmt.Errorf("failed to marshal merit list content: %w", err)
 	}
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
+}
 
-	if err := ctx.GetStub().PutState(regID, regJSON); err != nil {
-		return fmt.Errorf("failed to store registration: %w", err)
+// meritListStanding computes a student's standing from the released records written before
+// the cutoff, the same records Student.CurrentCGPA is computed over
+func (s *SmartContract) meritListStanding(ctx contractapi.TransactionContextInterface, rollNumber string, before time.Time) (*MeritListEntry, error) {
+	released, err := s.releasedRecords(ctx, rollNumber)
+	if err != nil {
+		return nil, err
 	}
 
-	// Composite key for student semester registrations
-	semRegKey, _ := ctx.GetStub().CreateCompositeKey(SemesterRegKey, []string{studentID, fmt.Sprintf("%d", semester), regID})
-	ctx.GetStub().PutState(semRegKey, []byte{0x00})
-
-	// Emit event
-	eventPayload := map[string]interface{}{
-		"regId":          regID,
-		"studentId":      studentID,
-		"semester":       semester,
-		"academicYear":   academicYear,
-		"facultyAdvisor": facultyAdvisor,
-		"registeredBy":   clientID,
+	records := []*AcademicRecord{}
+	for _, record := range released {
+		if record.Timestamp.Before(before) {
+			records = append(records, record)
+		}
 	}
-	eventJSON, _ := json.Marshal(eventPayload)
-	ctx.GetStub().SetEvent("StudentRegisteredForSemester", eventJSON)
+	records = gpaRecords(records)
 
-	return nil
+	entry := &MeritListEntry{RollNumber: rollNumber, SemestersCompleted: len(records)}
+	if len(records) > 0 {
+		entry.LatestSGPA = records[len(records)-1].SGPA
+	}
+	entry.CGPA, _, entry.CreditsEarned = cumulativeGPA(records)
+	return entry, nil
 }
 
-// GetSemesterRegistration retrieves a semester registration
-func (s *SmartContract) GetSemesterRegistration(ctx contractapi.TransactionContextInterface, regID string) (*SemesterRegistration, error) {
-	regJSON, err := ctx.GetStub().GetState(regID)
+// GenerateMeritList ranks the ACTIVE and GRADUATED students of a department's batch (enrollment
+// year) by CGPA as of cutoffDate (YYYY-MM-DD, inclusive) and stores the ranking as an immutable
+// merit list. Students without a released record by the cutoff are left out. Ties are broken,
+// in order, by: more credits earned, higher SGPA in the latest completed semester, and then
+// roll number ascending, so every student gets a distinct rank.
+func (s *SmartContract) GenerateMeritList(ctx contractapi.TransactionContextInterface,
+	department string, batch int, cutoffDate string) (*MeritList, error) {
+
+	department = strings.ToUpper(strings.TrimSpace(department))
+	if department == "" {
+		return nil, fmt.Errorf("department is required")
+	}
+	if batch < 1950 {
+		return nil, fmt.Errorf("invalid batch: %d", batch)
+	}
+	cutoff, err := time.Parse("2006-01-02", strings.TrimSpace(cutoffDate))
 	if err != nil {
-		return nil, fmt.Errorf("failed to read registration: %w", err)
+		return nil, fmt.Errorf("invalid cutoff date %q (expected YYYY-MM-DD)", cutoffDate)
 	}
-	if regJSON == nil {
-		return nil, fmt.Errorf("registration %s does not exist", regID)
+
+	txTimestamp, err := ctx.GetStub().GetTxTimestamp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	now := time.Unix(txTimestamp.Seconds, int64(txTimestamp.Nanos))
+	if cutoff.After(now) {
+		return nil, fmt.Errorf("cutoff date %s is in the future", cutoff.Format("2006-01-02"))
 	}
 
-	var reg SemesterRegistration
-	if err := json.Unmarshal(regJSON, &reg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal registration: %w", err)
+	meritListID := fmt.Sprintf("MERIT-%s-%d-%s", department, batch, cutoff.Format("20060102"))
+	existing, err := getAssetState(ctx, DocTypeMeritList, meritListID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merit list: %w", err)
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("merit list %s has already been generated", meritListID)
 	}
-	return &reg, nil
-}
 
-// GetSemesterRegistrationsByStudent returns all semester registrations for a student
-func (s *SmartContract) GetSemesterRegistrationsByStudent(ctx contractapi.TransactionContextInterface, studentID string) ([]*SemesterRegistration, error) {
-	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(SemesterRegKey, []string{studentID})
+	iter, err := ctx.GetStub().GetStateByPartialCompositeKey(StudentDeptKey, []string{department})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get registrations for student %s: %w", studentID, err)
+		return nil, fmt.Errorf("failed to query students by department: %w", err)
 	}
 	defer iter.Close()
 
-	var registrations []*SemesterRegistration
+	before := cutoff.AddDate(0, 0, 1)
+	entries := []MeritListEntry{}
 	for iter.HasNext() {
 		kv, err := iter.Next()
 		if err != nil {
-			continue
+			return nil, err
 		}
 		_, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
-		if err != nil || len(parts) < 3 {
+		if err != nil || len(parts) < 2 {
+			continue
+		}
+		student, err := s.readStudent(ctx, parts[1])
+		if err != nil || student.EnrollmentYear != batch {
+			continue
+		}
+		if student.Status != StatusActive && student.Status != StatusGraduated {
 			continue
 		}
-		regID := parts[2]
 
-		reg, err := s.GetSemesterRegistration(ctx, regID)
+		entry, err := s.meritListStanding(ctx, student.RollNumber, before)
 		if err != nil {
+			return nil, err
+		}
+		if entry.SemestersCompleted == 0 {
 			continue
 		}
-		registrations = append(registrations, reg)
+		entry.Name = student.Name
+		entry.CurrentCGPA = student.CurrentCGPA
+		entries = append(entries, *entry)
 	}
 
-	if registrations == nil {
-		registrations = []*SemesterRegistration{}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.CGPA != b.CGPA {
+			return a.CGPA > b.CGPA
+		}
+		if a.CreditsEarned != b.CreditsEarned {
+			return a.CreditsEarned > b.CreditsEarned
+		}
+		if a.LatestSGPA != b.LatestSGPA {
+			return a.LatestSGPA > b.LatestSGPA
+		}
+		return a.RollNumber < b.RollNumber
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
 	}
-	return registrations, nil
-}
 
-// ============================================================
-// SPRINT 3 ADDITIONS: CONSENT MANAGEMENT + DOCUMENT STATUS
-// ============================================================
+	clientID, err := ctx.GetClientIdentity().GetID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client identity: %w", err)
+	}
 
-// ConsentRecord stores a student's consent grant on the ledger
-type ConsentRecord struct {
-	ConsentID   string `json:"consentId"`
-	StudentID   string `json:"studentId"`
-	RequesterID string `json:"requesterId"`
-	Scope       string `json:"scope"`   // SEMESTER | FULL_RECORD
-	Status      string `json:"status"`  // ACTIVE | REVOKED
-	GrantedBy   string `json:"grantedBy"`
-	GrantedAt   string `json:"grantedAt"`
-	RevokedAt   string `json:"revokedAt,omitempty"`
-	RevokedBy   string `json:"revokedBy,omitempty"`
-	ExpiresAt   string `json:"expiresAt,omitempty"`
-}
+	list := &MeritList{
+		DocType:     DocTypeMeritList,
+		MeritListID: meritListID,
+		Department:  department,
+		Batch:       batch,
+		CutoffDate:  cutoff.Format("2006-01-02"),
+		Entries:     entries,
+		GeneratedBy: clientID,
+		GeneratedAt: now,
+		TxID:        ctx.GetStub().GetTxID(),
+	}
+	list.EntriesHash, err = meritListHash(list)
+	if err != nil {
+		return nil, err
+	}
 
-const ConsentKeyPrefix = "CONSENT"
+	listJSON, err := json.Marshal(list)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merit list: %w", err)
+	}
+	if err := putAssetState(ctx, DocTypeMeritList, meritListID, listJSON); err != nil {
+		return nil, fmt.Errorf("failed to store merit list: %w", err)
+	}
+	indexKey, err := ctx.GetStub().CreateCompositeKey(MeritListDeptKey, []string{department, fmt.Sprintf("%d", batch), meritListID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merit list index key: %w", err)
+	}
+	if err := ctx.GetStub().PutState(indexKey, []byte{0x00}); err != nil {
+		return nil, fmt.Errorf("failed to store merit list index: %w", err)
+	}
 
-// GrantConsent — student grants a requester (employer/institution) access to their records
-func (s *SmartContract) GrantConsent(ctx contractapi.TransactionContextInterface,
-	consentID, studentID, requesterID, scope, grantedBy, grantedAt string) error {
+	eventPayload := map[string]interface{}{
+		"meritListId": meritListID,
+		"department":  department,
+		"batch":       batch,
+		"cutoffDate":  list.CutoffDate,
+		"entryCount":  len(entries),
+		"entriesHash": list.EntriesHash,
+		"generatedBy": clientID,
+	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	ctx.GetStub().SetEvent("MeritListGenerated", eventJSON)
 
-	// Validate scope
-	if scope != "SEMESTER" && scope != "FULL_RECORD" {
-		return fmt.Errorf("invalid scope '%s': must be SEMESTER or FULL_RECORD", scope)
+	return list, nil
+}
+
+// readMeritList loads a merit list without access control
+func readMeritList(ctx contractapi.TransactionContextInterface, meritListID string) (*MeritList, error) {
+	listJSON, err := getAssetState(ctx, DocTypeMeritList, meritListID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read merit list: %w", err)
+	}
+	if listJSON == nil {
+		return nil, fmt.Errorf("merit list %s does not exist", meritListID)
+	}
+	var list MeritList
+	if err := json.Unmarshal(listJSON, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal merit list: %w", err)
 	}
+	return &list, nil
+}
 
-	// Check for existing active consent between same pair
-	existing, _ := s.CheckConsent(ctx, studentID, requesterID)
-	if existing {
-		return fmt.Errorf("active consent already exists for student %s and requester %s", studentID, requesterID)
+// GetMeritList returns a stored merit list
+func (s *SmartContract) GetMeritList(ctx contractapi.TransactionContextInterface, meritListID string) (*MeritList, error) {
+	list, err := readMeritList(ctx, meritListID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDepartmentAccess(ctx, list.Department); err != nil {
+		return nil, err
 	}
+	return list, nil
+}
 
-	consent := ConsentRecord{
-		ConsentID:   consentID,
-		StudentID:   studentID,
-		RequesterID: requesterID,
-		Scope:       scope,
-		Status:      "ACTIVE",
-		GrantedBy:   grantedBy,
-		GrantedAt:   grantedAt,
+// GetMeritListsByDepartment returns a page of a department's merit lists, optionally limited
+// to one batch (0 for all)
+func (s *SmartContract) GetMeritListsByDepartment(ctx contractapi.TransactionContextInterface,
+	department string, batch int, bookmark string, pageSize int) (*MeritListPage, error) {
+
+	department = strings.ToUpper(department)
+	if err := checkDepartmentAccess(ctx, department); err != nil {
+		return nil, err
+	}
+
+	attributes := []string{department}
+	if batch > 0 {
+		attributes = append(attributes, fmt.Sprintf("%d", batch))
+	}
+	keys, nextBookmark, err := pageIndexKeys(ctx, []pageSource{{objectType: MeritListDeptKey, attributes: attributes}}, bookmark, pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query merit lists: %w", err)
 	}
 
-	consentJSON, err := json.Marshal(consent)
-	if err != nil {
-		return fmt.Errorf("failed to marshal consent: %w", err)
+	lists := []*MeritList{}
+	for _, key := range keys {
+		_, parts, err := ctx.GetStub().SplitCompositeKey(key)
+		if err != nil || len(parts) < 3 {
+			continue
+		}
+		list, err := readMeritList(ctx, parts[2])
+		if err != nil {
+			continue
+		}
+		lists = append(lists, list)
 	}
 
-	// Primary key: CONSENT~consentID
-	key, err := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
+	return &MeritListPage{
+		Records:       lists,
+		Bookmark:      nextBookmark,
+		RecordCount:   len(lists),
+		FilteredCount: len(keys) - len(lists),
+		HasMore:       nextBookmark != "",
+	}, nil
+}
+
+// VerifyMeritList recomputes a merit list's hash from its stored content. When expectedHash is
+// given (for instance from a published copy) it must match as well.
+func (s *SmartContract) VerifyMeritList(ctx contractapi.TransactionContextInterface, meritListID, expectedHash string) (*MeritListVerification, error) {
+	list, err := readMeritList(ctx, meritListID)
 	if err != nil {
-		return fmt.Errorf("failed to create consent key: %w", err)
+		return nil, err
 	}
-	if err := ctx.GetStub().PutState(key, consentJSON); err != nil {
-		return fmt.Errorf("failed to store consent: %w", err)
+	computed, err := meritListHash(list)
+	if err != nil {
+		return nil, err
 	}
 
-	// Index key for lookup by student+requester: CONSENT_IDX~studentID~requesterID~consentID
-	idxKey, _ := ctx.GetStub().CreateCompositeKey("CONSENT_IDX", []string{studentID, requesterID, consentID})
-	_ = ctx.GetStub().PutState(idxKey, []byte(consentID))
+	result := &MeritListVerification{
+		MeritListID:  meritListID,
+		StoredHash:   list.EntriesHash,
+		ComputedHash: computed,
+	}
+	expectedHash = strings.ToLower(strings.TrimSpace(expectedHash))
+	switch {
+	case computed != list.EntriesHash:
+		result.Message = "merit list content does not match its stored hash"
+	case expectedHash != "" && expectedHash != computed:
+		result.Message = "merit list hash does not match the expected hash"
+	default:
+		result.Valid = true
+		result.Message = "merit list is intact"
+	}
+	return result, nil
+}
 
-	// Emit event
-	_ = ctx.GetStub().SetEvent("ConsentGranted", consentJSON)
+// ============================================================
+// AUDIT TRAIL
+// ============================================================
 
-	return nil
+// AuditEntry is one version of a key from the ledger's history. Value holds the JSON as
+// written by the transaction and Changes lists the top-level fields that differ from the
+// previous version; values in Changes are JSON text.
+type AuditEntry struct {
+	TxID      string        `json:"txId"`
+	Timestamp time.Time     `json:"timestamp"`
+	IsDelete  bool          `json:"isDelete"`
+	Value     string        `json:"value"`
+	Changes   []FieldChange `json:"changes"`
 }
 
-// RevokeConsent — student revokes a previously granted consent
-func (s *SmartContract) RevokeConsent(ctx contractapi.TransactionContextInterface,
-	consentID, revokedBy, revokedAt string) error {
-
-	key, err := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
-	if err != nil {
-		return fmt.Errorf("failed to create consent key: %w", err)
+// diffJSONFields compares two JSON objects field by field. Either side may be empty.
+func diffJSONFields(previous, current []byte) []FieldChange {
+	before := map[string]json.RawMessage{}
+	after := map[string]json.RawMessage{}
+	if len(previous) > 0 {
+		_ = json.Unmarshal(previous, &before)
 	}
-
-	consentJSON, err := ctx.GetStub().GetState(key)
-	if err != nil || consentJSON == nil {
-		return fmt.Errorf("consent %s not found", consentID)
+	if len(current) > 0 {
+		_ = json.Unmarshal(current, &after)
 	}
 
-	var consent ConsentRecord
-	if err := json.Unmarshal(consentJSON, &consent); err != nil {
-		return fmt.Errorf("failed to unmarshal consent: %w", err)
+	fields := make([]string, 0, len(before)+len(after))
+	for field := range before {
+		fields = append(fields, field)
 	}
-
-	if consent.Status == "REVOKED" {
-		return fmt.Errorf("consent %s is already revoked", consentID)
+	for field := range after {
+		if _, ok := before[field]; !ok {
+			fields = append(fields, field)
+		}
 	}
+	sort.Strings(fields)
 
-	consent.Status = "REVOKED"
-	consent.RevokedBy = revokedBy
-	consent.RevokedAt = revokedAt
+	changes := []FieldChange{}
+	for _, field := range fields {
+		oldValue, newValue := string(before[field]), string(after[field])
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	return changes
+}
 
-	updatedJSON, err := json.Marshal(consent)
+// readAuditTrail walks the ledger history of an asset, oldest version first. Versions
+// written under the legacy untyped key come before those under the typed key.
+func readAuditTrail(ctx contractapi.TransactionContextInterface, docType, id string) ([]AuditEntry, error) {
+	key, err := assetKey(ctx, docType, id)
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated consent: %w", err)
+		return nil, err
 	}
 
-	if err := ctx.GetStub().PutState(key, updatedJSON); err != nil {
-		return fmt.Errorf("failed to update consent: %w", err)
+	trail := []AuditEntry{}
+	var previous []byte
+	for _, historyKey := range []string{id, key} {
+		if historyKey == "" {
+			continue
+		}
+		previous, err = appendAuditTrail(ctx, docType, historyKey, previous, &trail)
+		if err != nil {
+			return nil, err
+		}
 	}
-
-	_ = ctx.GetStub().SetEvent("ConsentRevoked", updatedJSON)
-
-	return nil
+	return trail, nil
 }
 
-// CheckConsent — returns true if an active consent exists for the studentID+requesterID pair
-func (s *SmartContract) CheckConsent(ctx contractapi.TransactionContextInterface,
-	studentID, requesterID string) (bool, error) {
-
-	iter, err := ctx.GetStub().GetStateByPartialCompositeKey("CONSENT_IDX", []string{studentID, requesterID})
+// appendAuditTrail adds the versions of one key to trail, skipping versions of a legacy key
+// that belong to another asset type, and returns the last value seen
+func appendAuditTrail(ctx contractapi.TransactionContextInterface, docType, key string, previous []byte, trail *[]AuditEntry) ([]byte, error) {
+	iter, err := ctx.GetStub().GetHistoryForKey(key)
 	if err != nil {
-		return false, err
+		return nil, fmt.Errorf("failed to get history for %s: %w", key, err)
 	}
 	defer iter.Close()
 
 	for iter.HasNext() {
-		kv, err := iter.Next()
+		modification, err := iter.Next()
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to iterate history for %s: %w", key, err)
 		}
-		consentID := string(kv.Value)
-		key, _ := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
-		consentJSON, err := ctx.GetStub().GetState(key)
-		if err != nil || consentJSON == nil {
+		if !modification.IsDelete && assetTypeOf(modification.Value) != docType {
 			continue
 		}
-		var consent ConsentRecord
-		if err := json.Unmarshal(consentJSON, &consent); err != nil {
-			continue
+
+		entry := AuditEntry{
+			TxID:     modification.TxId,
+			IsDelete: modification.IsDelete,
+			Value:    string(modification.Value),
 		}
-		if consent.Status == "ACTIVE" {
-			return true, nil
+		if modification.Timestamp != nil {
+			entry.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos))
 		}
+		entry.Changes = diffJSONFields(previous, modification.Value)
+		*trail = append(*trail, entry)
+		previous = modification.Value
 	}
+	return previous, nil
+}
 
-	return false, nil
+// GetStudentAuditTrail returns every version of a student's public record
+func (s *SmartContract) GetStudentAuditTrail(ctx contractapi.TransactionContextInterface, rollNumber string) ([]AuditEntry, error) {
+	if err := s.authorizeStudentReadByID(ctx, rollNumber); err != nil {
+		return nil, err
+	}
+	return readAuditTrail(ctx, DocTypeStudent, rollNumber)
 }
 
-// GetConsentsByStudent — returns all consent records (active and revoked) for a student
-func (s *SmartContract) GetConsentsByStudent(ctx contractapi.TransactionContextInterface,
-	studentID string) ([]*ConsentRecord, error) {
+// GetRecordAuditTrail returns every version of an academic record's public state. Versions
+// written before the record was released are stubs; their grades never reach public history.
+func (s *SmartContract) GetRecordAuditTrail(ctx contractapi.TransactionContextInterface, recordID string) ([]AuditEntry, error) {
+	record, err := s.readRecordState(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeRecordRead(ctx, record); err != nil {
+		return nil, err
+	}
+	return readAuditTrail(ctx, DocTypeRecord, recordID)
+}
 
-	iter, err := ctx.GetStub().GetStateByPartialCompositeKey("CONSENT_IDX", []string{studentID})
+// GetCertificateAuditTrail returns every version of a certificate, including revocation
+func (s *SmartContract) GetCertificateAuditTrail(ctx contractapi.TransactionContextInterface, certificateID string) ([]AuditEntry, error) {
+	certJSON, err := getAssetState(ctx, DocTypeCertificate, certificateID)
 	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %v", err)
+	}
+	if certJSON == nil {
+		return nil, fmt.Errorf("certificate %s does not exist", certificateID)
+	}
+	var certificate Certificate
+	if err := json.Unmarshal(certJSON, &certificate); err != nil {
 		return nil, err
 	}
-	defer iter.Close()
+	if err := s.authorizeStudentReadByID(ctx, certificate.StudentID); err != nil {
+		return nil, err
+	}
+	return readAuditTrail(ctx, DocTypeCertificate, certificateID)
+}
 
-	var consents []*ConsentRecord
-	seen := map[string]bool{}
+// ============================================================
+// INDEX MAINTENANCE
+// ============================================================
 
-	for iter.HasNext() {
-		kv, err := iter.Next()
+// indexedPrefixes lists the public secondary indexes that VerifyIndexes and ReindexAll
+// reconcile against the primary student, record, certificate, document, registration, merit
+// list and grading scheme state
+var indexedPrefixes = []string{
+	StudentAllKey, StudentDeptKey, StudentYearKey, StudentStatusKey,
+	StudentRecordKey, RecordSemesterKey, RecordStatusKey, RecordDeptKey, RecordDeptYearKey,
+	CertStudentKey,
+	DocumentKey, DocumentHashKey, DocumentHashHistoryKey,
+	SemesterRegKey,
+	MeritListDeptKey, GradingSchemeProgramKey,
+}
+
+// IndexReport describes how the secondary indexes differ from what primary state implies.
+// Keys are rendered as "prefix[attr1,attr2]".
+type IndexReport struct {
+	Scanned  map[string]int `json:"scanned"`  // Primary assets scanned, by asset type
+	Missing  []string       `json:"missing"`  // Expected entries that were absent
+	Orphaned []string       `json:"orphaned"` // Entries no primary asset accounts for
+	Stale    []string       `json:"stale"`    // Entries holding a value other than the marker
+	Repaired bool           `json:"repaired"`
+	TxID     string         `json:"txId,omitempty"`
+}
+
+// expectedIndexKeys returns the index entries a primary asset should have, or nil if the
+// asset type has no secondary indexes
+func expectedIndexKeys(docType string, value []byte) [][]string {
+	switch docType {
+	case DocTypeStudent:
+		var student Student
+		if err := json.Unmarshal(value, &student); err != nil || student.RollNumber == "" {
+			return nil
+		}
+		return [][]string{
+			{StudentAllKey, student.RollNumber},
+			{StudentDeptKey, student.Department, student.RollNumber},
+			{StudentYearKey, fmt.Sprintf("%d", student.EnrollmentYear), student.RollNumber},
+			{StudentStatusKey, student.Status, student.RollNumber},
+		}
+	case DocTypeRecord:
+		var record AcademicRecord
+		if err := json.Unmarshal(value, &record); err != nil || record.RecordID == "" {
+			return nil
+		}
+		keys := [][]string{
+			{StudentRecordKey, record.StudentID, record.RecordID},
+			{RecordSemesterKey, fmt.Sprintf("%d", record.Semester), record.StudentID, record.RecordID},
+			{RecordStatusKey, record.Status, record.StudentID, record.RecordID},
+			{RecordDeptKey, record.Department, record.StudentID, record.RecordID},
+		}
+		// Records created before the academic year was stored carry no year index
+		if record.AcademicYear != "" {
+			keys = append(keys, []string{RecordDeptYearKey, record.Department, record.AcademicYear, record.StudentID, record.RecordID})
+		}
+		return keys
+	case DocTypeCertificate:
+		var cert Certificate
+		if err := json.Unmarshal(value, &cert); err != nil || cert.CertificateID == "" {
+			return nil
+		}
+		return [][]string{
+			{CertStudentKey, cert.StudentID, cert.CertificateID},
+		}
+	case DocTypeDocument:
+		var doc DocumentUpload
+		if err := json.Unmarshal(value, &doc); err != nil || doc.DocID == "" {
+			return nil
+		}
+		hashIndex := DocumentHashKey
+		if doc.Status != "" && doc.Status != DocStatusCurrent {
+			hashIndex = DocumentHashHistoryKey
+		}
+		// Sensitive documents keep their indexes in private data
+		return [][]string{
+			{DocumentKey, doc.StudentID, doc.DocID},
+			{hashIndex, doc.SHA256Hash, doc.DocID},
+		}
+	case DocTypeRegistration:
+		var reg SemesterRegistration
+		if err := json.Unmarshal(value, &reg); err != nil || reg.RegID == "" {
+			return nil
+		}
+		return [][]string{
+			{SemesterRegKey, reg.StudentID, fmt.Sprintf("%d", reg.Semester), reg.RegID},
+		}
+	case DocTypeMeritList:
+		var list MeritList
+		if err := json.Unmarshal(value, &list); err != nil || list.MeritListID == "" {
+			return nil
+		}
+		return [][]string{
+			{MeritListDeptKey, list.Department, fmt.Sprintf("%d", list.Batch), list.MeritListID},
+		}
+	case DocTypeGradingScheme:
+		var scheme GradingScheme
+		if err := json.Unmarshal(value, &scheme); err != nil || scheme.SchemeID == "" {
+			return nil
+		}
+		return [][]string{
+			{GradingSchemeProgramKey, scheme.Program, scheme.SchemeID},
+		}
+	}
+	return nil
+}
+
+// reconcileIndexes compares every public secondary index against primary state and, when
+// repair is set, adds missing entries, deletes orphaned ones and rewrites stale values.
+// Sensitive documents keep their indexes in private data and are not covered.
+func reconcileIndexes(ctx contractapi.TransactionContextInterface, repair bool) (*IndexReport, error) {
+	report := &IndexReport{Scanned: map[string]int{}, Missing: []string{}, Orphaned: []string{}, Stale: []string{}}
+
+	// Collect the entries implied by primary state
+	expected := map[string]string{}
+	err := scanAssets(ctx, assetTypes, func(docType, assetKey string, value []byte) error {
+		report.Scanned[docType]++
+		for _, entry := range expectedIndexKeys(docType, value) {
+			key, err := ctx.GetStub().CreateCompositeKey(entry[0], entry[1:])
+			if err != nil {
+				return fmt.Errorf("failed to create index key for %s: %w", assetKey, err)
+			}
+			expected[key] = fmt.Sprintf("%s[%s]", entry[0], strings.Join(entry[1:], ","))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Walk each index and classify what is actually there
+	present := map[string]bool{}
+	for _, prefix := range indexedPrefixes {
+		indexIter, err := ctx.GetStub().GetStateByPartialCompositeKey(prefix, []string{})
 		if err != nil {
-			continue
+			return nil, fmt.Errorf("failed to scan index %s: %w", prefix, err)
 		}
-		consentID := string(kv.Value)
-		if seen[consentID] {
-			continue
+		for indexIter.HasNext() {
+			kv, err := indexIter.Next()
+			if err != nil {
+				indexIter.Close()
+				return nil, fmt.Errorf("failed to iterate index %s: %w", prefix, err)
+			}
+			present[kv.Key] = true
+
+			label, ok := expected[kv.Key]
+			if !ok {
+				objectType, parts, err := ctx.GetStub().SplitCompositeKey(kv.Key)
+				if err != nil {
+					indexIter.Close()
+					return nil, fmt.Errorf("failed to split index key: %w", err)
+				}
+				report.Orphaned = append(report.Orphaned, fmt.Sprintf("%s[%s]", objectType, strings.Join(parts, ",")))
+				if repair {
+					if err := ctx.GetStub().DelState(kv.Key); err != nil {
+						indexIter.Close()
+						return nil, fmt.Errorf("failed to delete orphaned index entry: %w", err)
+					}
+				}
+				continue
+			}
+			if len(kv.Value) != 1 || kv.Value[0] != 0x00 {
+				report.Stale = append(report.Stale, label)
+				if repair {
+					if err := ctx.GetStub().PutState(kv.Key, []byte{0x00}); err != nil {
+						indexIter.Close()
+						return nil, fmt.Errorf("failed to rewrite index entry: %w", err)
+					}
+				}
+			}
 		}
-		seen[consentID] = true
+		indexIter.Close()
+	}
 
-		key, _ := ctx.GetStub().CreateCompositeKey(ConsentKeyPrefix, []string{consentID})
-		consentJSON, err := ctx.GetStub().GetState(key)
-		if err != nil || consentJSON == nil {
+	expectedKeys := make([]string, 0, len(expected))
+	for key := range expected {
+		expectedKeys = append(expectedKeys, key)
+	}
+	sort.Strings(expectedKeys)
+	for _, key := range expectedKeys {
+		if present[key] {
 			continue
 		}
-		var c ConsentRecord
-		if err := json.Unmarshal(consentJSON, &c); err != nil {
-			continue
+		report.Missing = append(report.Missing, expected[key])
+		if repair {
+			if err := ctx.GetStub().PutState(key, []byte{0x00}); err != nil {
+				return nil, fmt.Errorf("failed to add missing index entry: %w", err)
+			}
 		}
-		consents = append(consents, &c)
 	}
 
-	if consents == nil {
-		consents = []*ConsentRecord{}
+	sort.Strings(report.Orphaned)
+	sort.Strings(report.Stale)
+	report.Repaired = repair
+	if repair {
+		report.TxID = ctx.GetStub().GetTxID()
 	}
-	return consents, nil
+	return report, nil
 }
 
-// UpdateDocumentStatus — advances or reverts a document through the 5-stage pipeline.
-// Valid transitions:
-//   UPLOADED → UNDER_REVIEW → AUTHENTICATED → APPROVED → ON_CHAIN
-//   Any stage → UPLOADED (return for re-authentication)
-func (s *SmartContract) UpdateDocumentStatus(ctx contractapi.TransactionContextInterface,
-	docID, newStatus, updatedBy string) error {
+// VerifyIndexes reports missing, orphaned and stale secondary index entries without changing anything
+func (s *SmartContract) VerifyIndexes(ctx contractapi.TransactionContextInterface) (*IndexReport, error) {
+	return reconcileIndexes(ctx, false)
+}
 
-	// Valid pipeline statuses
-	validStatuses := map[string]bool{
-		"UPLOADED":      true,
-		"UNDER_REVIEW":  true,
-		"AUTHENTICATED": true,
-		"APPROVED":      true,
-		"ON_CHAIN":      true,
+// ReindexAll repairs the secondary indexes so they match primary state and reports what changed
+func (s *SmartContract) ReindexAll(ctx contractapi.TransactionContextInterface) (*IndexReport, error) {
+	report, err := reconcileIndexes(ctx, true)
+	if err != nil {
+		return nil, err
 	}
-	if !validStatuses[newStatus] {
-		return fmt.Errorf("invalid document status '%s'", newStatus)
+
+	eventPayload := map[string]interface{}{
+		"missing":  len(report.Missing),
+		"orphaned": len(report.Orphaned),
+		"stale":    len(report.Stale),
+		"txId":     report.TxID,
 	}
+	eventJSON, _ := json.Marshal(eventPayload)
+	_ = ctx.GetStub().SetEvent("IndexesRebuilt", eventJSON)
 
-	// Attempt to retrieve the underlying AcademicRecord or Document
-	// The doc is stored with key "DOC~{docID}"
-	docKey, err := ctx.GetStub().CreateCompositeKey("DOC", []string{docID})
-	if err != nil {
-		return fmt.Errorf("failed to create doc key: %w", err)
+	return report, nil
+}
+
+// ============================================================
+// TRANSACTION POLICY
+// ============================================================
+
+// TransactionPolicy declares which identities may invoke a transaction. It is checked by
+// enforceTransactionPolicy before every transaction; checks that depend on the data being
+// touched (department ownership, a student's own records, verifier consent) stay inside
+// the transaction itself.
+type TransactionPolicy struct {
+	Function string              `json:"function"`
+	Roles    map[string][]string `json:"roles"`    // MSP ID -> accepted role attributes, empty = any role
+	Students bool                `json:"students"` // student identities may invoke, limited to their own data
+}
+
+// EffectivePermissions lists the transactions the calling identity may invoke
+type EffectivePermissions struct {
+	MSPID      string   `json:"mspId"`
+	Role       string   `json:"role,omitempty"`
+	RollNumber string   `json:"rollNumber,omitempty"`
+	IsStudent  bool     `json:"isStudent"`
+	Functions  []string `json:"functions"`
+}
+
+// MSP role sets shared by the policy table. NITWarangalMSP entries never match student
+// identities, which are only admitted through the Students flag.
+var (
+	policyAdmin       = map[string][]string{NITWarangalMSP: nil}
+	policyAdminOrDept = map[string][]string{NITWarangalMSP: nil, DepartmentsMSP: nil}
+	policyAllOrgs     = map[string][]string{NITWarangalMSP: nil, DepartmentsMSP: nil, VerifiersMSP: nil}
+)
+
+// transactionPolicies is the policy table. Every exported transaction must have an entry;
+// main refuses to start otherwise and the hook denies functions that are not listed.
+var transactionPolicies = map[string]TransactionPolicy{
+	// Students
+	"InitLedger":                 {Roles: policyAdmin},
+	"CreateStudent":              {Roles: policyAdmin},
+	"GetStudentPrivateDetails":   {Roles: policyAdmin, Students: true},
+	"ResolveAadhaarHash":         {Roles: policyAdmin},
+	"SetIdentityHashKey":         {Roles: policyAdmin},
+	"RehashStudentIdentity":      {Roles: policyAdmin},
+	"VerifyStudentIdentity":      {Roles: policyAdmin, Students: true},
+	"GetStudent":                 {Roles: policyAllOrgs, Students: true},
+	"UpdateStudentStatus":        {Roles: policyAdmin},
+	"ReadmitStudent":             {Roles: policyAdmin},
+	"GetStudentStatusHistory":    {Roles: policyAllOrgs, Students: true},
+	"UpdateStudentContactInfo":   {Roles: policyAdmin},
+	"PurgeStudentPrivateDetails": {Roles: policyAdmin},
+	"GetStudentPurgeTombstone":   {Roles: policyAdmin},
+	"BranchChange":               {Roles: policyAdmin},
+	"GetBranchChangeHistory":     {Roles: policyAllOrgs, Students: true},
+	"UpdateStudentProfile":       {Roles: policyAdmin},
+	"GetStudentProfileHistory":   {Roles: policyAllOrgs, Students: true},
+	"StudentExists":              {Roles: policyAllOrgs, Students: true},
+	"GetStudentHistory":          {Roles: policyAllOrgs, Students: true},
+	"GetStudentCGPA":             {Roles: policyAllOrgs, Students: true},
+	"GetAllStudents":             {Roles: policyAllOrgs, Students: true},
+	"GetStudentsByFaculty":       {Roles: policyAdminOrDept},
+	"GetStudentsByDepartment":    {Roles: policyAdminOrDept},
+
+	// Academic records
+	"CreateAcademicRecord":    {Roles: policyAdminOrDept},
+	"GetAcademicRecord":       {Roles: policyAllOrgs, Students: true},
+	"ApproveAcademicRecord":   {Roles: policyAdmin},
+	"SupersedeAcademicRecord": {Roles: policyAdmin},
+
+	// Certificates
+	"IssueCertificate":         {Roles: policyAdmin},
+	"GetCertificate":           {Roles: policyAllOrgs, Students: true},
+	"VerifyCertificate":        {Roles: policyAllOrgs, Students: true},
+	"RevokeCertificate":        {Roles: policyAdmin},
+	"GetCertificatesByStudent": {Roles: policyAllOrgs, Students: true},
+
+	// Paginated queries
+	"QueryStudentsByDepartment": {Roles: policyAdminOrDept},
+	"QueryStudentsByYear":       {Roles: policyAllOrgs, Students: true},
+	"QueryStudentsByStatus":     {Roles: policyAllOrgs, Students: true},
+	"QueryRecordsBySemester":    {Roles: policyAllOrgs, Students: true},
+	"QueryRecordsByStatus":      {Roles: policyAllOrgs, Students: true},
+	"QueryPendingRecords":       {Roles: policyAdminOrDept},
+	"QueryRecordsByDepartment":  {Roles: policyAdminOrDept},
+
+	// Departments and course offerings
+	"CreateDepartment":       {Roles: policyAdmin},
+	"GetDepartment":          {Roles: policyAllOrgs, Students: true},
+	"GetAllDepartments":      {Roles: policyAllOrgs, Students: true},
+	"UpdateDepartment":       {Roles: policyAdmin},
+	"CreateCourseOffering":   {Roles: policyAdminOrDept},
+	"GetCourseOffering":      {Roles: policyAllOrgs, Students: true},
+	"GetCoursesByDepartment": {Roles: policyAllOrgs, Students: true},
+	"UpdateCourseOffering":   {Roles: policyAdminOrDept},
+
+	// Approval workflow
+	"GetApprovalStatus": {Roles: policyAllOrgs, Students: true},
+	"SubmitForApproval": {Roles: policyAdminOrDept},
+	"FacultyApprove": {Roles: map[string][]string{
+		NITWarangalMSP: nil,
+		DepartmentsMSP: {RoleFaculty, RoleHOD, RoleDepartment},
+	}},
+	"HODApprove": {Roles: map[string][]string{
+		NITWarangalMSP: nil,
+		DepartmentsMSP: {RoleHOD, RoleDepartment},
+	}},
+	"ExamSectionApprove":  {Roles: policyAdmin},
+	"DeanAcademicApprove": {Roles: policyAdmin},
+	"DACApprove": {Roles: map[string][]string{
+		NITWarangalMSP: nil,
+		DepartmentsMSP: {RoleDAC, RoleDepartment},
+	}},
+	"RejectRecord": {Roles: policyAdminOrDept},
+
+	// Documents
+	"UploadDocument":        {Roles: policyAdminOrDept},
+	"GetDocument":           {Roles: policyAllOrgs, Students: true},
+	"VerifyDocumentByHash":  {Roles: policyAllOrgs, Students: true},
+	"GetDocumentsByStudent": {Roles: policyAllOrgs, Students: true},
+	"VerifyDocument":        {Roles: policyAdminOrDept},
+	"ReplaceDocument":       {Roles: policyAdminOrDept},
+	"RetractDocument":       {Roles: policyAdminOrDept},
+	"UpdateDocumentStatus":  {Roles: policyAdminOrDept},
+
+	// Semester registration
+	"RegisterForSemester":               {Roles: policyAdminOrDept},
+	"GetSemesterRegistration":           {Roles: policyAllOrgs, Students: true},
+	"GetSemesterRegistrationsByStudent": {Roles: policyAllOrgs, Students: true},
+
+	// Consent
+	"GrantConsent":         {Roles: policyAdmin, Students: true},
+	"RevokeConsent":        {Roles: policyAdmin, Students: true},
+	"CheckConsent":         {Roles: policyAllOrgs, Students: true},
+	"GetConsentsByStudent": {Roles: policyAllOrgs, Students: true},
+
+	// Rich queries
+	"QueryStudents":     {Roles: policyAdminOrDept, Students: true},
+	"QueryRecords":      {Roles: policyAdminOrDept, Students: true},
+	"QueryCertificates": {Roles: policyAdmin, Students: true},
+
+	// Admission categories
+	"SetAdmissionCategories":     {Roles: policyAdmin},
+	"GetAdmissionCategories":     {Roles: policyAllOrgs, Students: true},
+	"GetAdmissionCategoryReport": {Roles: policyAdminOrDept},
+
+	// Grading schemes
+	"CreateGradingScheme":       {Roles: policyAdmin},
+	"GetGradingScheme":          {Roles: policyAllOrgs, Students: true},
+	"GetGradingSchemes":         {Roles: policyAllOrgs, Students: true},
+	"GetEffectiveGradingScheme": {Roles: policyAllOrgs, Students: true},
+
+	// GPA
+	"RecomputeStudentCGPA": {Roles: policyAdmin},
+
+	// Result analytics
+	"GetDepartmentResultAnalytics": {Roles: policyAdminOrDept},
+
+	// Merit lists
+	"GenerateMeritList":         {Roles: policyAdmin},
+	"GetMeritList":              {Roles: policyAdminOrDept},
+	"GetMeritListsByDepartment": {Roles: policyAdminOrDept},
+	"VerifyMeritList":           {Roles: policyAllOrgs, Students: true},
+
+	// Audit trail
+	"GetStudentAuditTrail":     {Roles: policyAllOrgs, Students: true},
+	"GetRecordAuditTrail":      {Roles: policyAllOrgs, Students: true},
+	"GetCertificateAuditTrail": {Roles: policyAllOrgs, Students: true},
+
+	// Asset keys
+	"MigrateAssetKeys": {Roles: policyAdmin},
+
+	// Index maintenance
+	"VerifyIndexes": {Roles: policyAdmin},
+	"ReindexAll":    {Roles: policyAdmin},
+
+	// Policy
+	"GetTransactionPolicies":  {Roles: policyAllOrgs, Students: true},
+	"GetEffectivePermissions": {Roles: policyAllOrgs, Students: true},
+}
+
+// allows reports whether the policy admits the caller
+func (p TransactionPolicy) allows(caller *callerIdentity) bool {
+	if caller.isStudent() {
+		return p.Students && caller.MSPID == NITWarangalMSP
 	}
 
-	docJSON, err := ctx.GetStub().GetState(docKey)
-	if err != nil {
-		return fmt.Errorf("failed to retrieve document %s: %w", docID, err)
+	roles, ok := p.Roles[caller.MSPID]
+	if !ok {
+		return false
 	}
-	if docJSON == nil {
-		return fmt.Errorf("document %s does not exist", docID)
+	if len(roles) == 0 {
+		return true
 	}
-
-	// Parse as a generic map so this works regardless of exact struct shape
-	var doc map[string]interface{}
-	if err := json.Unmarshal(docJSON, &doc); err != nil {
-		return fmt.Errorf("failed to parse document %s: %w", docID, err)
+	for _, role := range roles {
+		if role == caller.Role {
+			return true
+		}
 	}
+	return false
+}
 
-	// Validate ordering when advancing (regression to UPLOADED always allowed)
-	stageOrder := map[string]int{
-		"UPLOADED":      0,
-		"UNDER_REVIEW":  1,
-		"AUTHENTICATED": 2,
-		"APPROVED":      3,
-		"ON_CHAIN":      4,
+// invokedFunctionName returns the transaction name as contractapi resolves it: without the
+// contract namespace and with the first letter upper-cased
+func invokedFunctionName(ctx contractapi.TransactionContextInterface) string {
+	function, _ := ctx.GetStub().GetFunctionAndParameters()
+	if idx := strings.LastIndex(function, ":"); idx >= 0 {
+		function = function[idx+1:]
 	}
-	currentStatus, _ := doc["documentStatus"].(string)
-	if currentStatus == "" {
-		currentStatus = "UPLOADED"
+	if function == "" {
+		return ""
 	}
+	r, size := utf8.DecodeRuneInString(function)
+	return string(unicode.ToUpper(r)) + function[size:]
+}
 
-	if newStatus != "UPLOADED" {
-		if stageOrder[newStatus] != stageOrder[currentStatus]+1 {
-			return fmt.Errorf("invalid transition from %s to %s: must advance exactly one stage at a time", currentStatus, newStatus)
+// enforceTransactionPolicy is the contract's BeforeTransaction hook. It rejects the
+// invocation unless the policy table admits the caller for the requested function.
+func enforceTransactionPolicy(ctx contractapi.TransactionContextInterface) error {
+	function := invokedFunctionName(ctx)
+	policy, ok := transactionPolicies[function]
+	if !ok {
+		return fmt.Errorf("unauthorized: no policy defined for %s", function)
+	}
+
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return err
+	}
+	if !policy.allows(caller) {
+		if caller.isStudent() {
+			return fmt.Errorf("unauthorized: students cannot invoke %s", function)
 		}
+		return fmt.Errorf("unauthorized: %s (role '%s') cannot invoke %s", caller.MSPID, caller.Role, function)
 	}
+	return nil
+}
 
-	// Update fields
-	doc["documentStatus"] = newStatus
-	doc["statusUpdatedBy"] = updatedBy
-	doc["statusUpdatedAt"] = ctx.GetStub().GetTxTimestamp
+// checkPolicyCoverage verifies that every transaction exposed by the contract has a policy
+func checkPolicyCoverage(contract *SmartContract) error {
+	base := reflect.TypeOf(&contractapi.Contract{})
+	contractType := reflect.TypeOf(contract)
 
-	updatedJSON, err := json.Marshal(doc)
-	if err != nil {
-		return fmt.Errorf("failed to marshal updated document: %w", err)
+	var missing []string
+	for i := 0; i < contractType.NumMethod(); i++ {
+		name := contractType.Method(i).Name
+		if _, inherited := base.MethodByName(name); inherited {
+			continue
+		}
+		if _, ok := transactionPolicies[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("transactions without a policy: %s", strings.Join(missing, ", "))
 	}
+	return nil
+}
 
-	if err := ctx.GetStub().PutState(docKey, updatedJSON); err != nil {
-		return fmt.Errorf("failed to update document status: %w", err)
+// GetTransactionPolicies returns the policy table, sorted by function name
+func (s *SmartContract) GetTransactionPolicies(ctx contractapi.TransactionContextInterface) ([]TransactionPolicy, error) {
+	policies := make([]TransactionPolicy, 0, len(transactionPolicies))
+	for function, policy := range transactionPolicies {
+		policy.Function = function
+		policies = append(policies, policy)
 	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Function < policies[j].Function })
+	return policies, nil
+}
 
-	eventPayload := map[string]string{
-		"docId":     docID,
-		"oldStatus": currentStatus,
-		"newStatus": newStatus,
-		"updatedBy": updatedBy,
+// GetEffectivePermissions returns the transactions the calling identity may invoke
+func (s *SmartContract) GetEffectivePermissions(ctx contractapi.TransactionContextInterface) (*EffectivePermissions, error) {
+	caller, err := getCaller(ctx)
+	if err != nil {
+		return nil, err
 	}
-	eventJSON, _ := json.Marshal(eventPayload)
-	_ = ctx.GetStub().SetEvent("DocumentStatusUpdated", eventJSON)
 
-	return nil
+	functions := []string{}
+	for function, policy := range transactionPolicies {
+		if policy.allows(caller) {
+			functions = append(functions, function)
+		}
+	}
+	sort.Strings(functions)
+
+	return &EffectivePermissions{
+		MSPID:      caller.MSPID,
+		Role:       caller.Role,
+		RollNumber: caller.RollNumber,
+		IsStudent:  caller.isStudent(),
+		Functions:  functions,
+	}, nil
 }
 
 // ============================================================
@@ -3756,7 +8955,15 @@ func (s *SmartContract) UpdateDocumentStatus(ctx contractapi.TransactionContextI
 
 
 func main() {
-	chaincode, err := contractapi.NewChaincode(&SmartContract{})
+	contract := new(SmartContract)
+	contract.BeforeTransaction = enforceTransactionPolicy
+
+	if err := checkPolicyCoverage(contract); err != nil {
+		fmt.Printf("Error creating academic records chaincode: %v\n", err)
+		return
+	}
+
+	chaincode, err := contractapi.NewChaincode(contract)
 	if err != nil {
 		fmt.Printf("Error creating academic records chaincode: %v\n", err)
 		return
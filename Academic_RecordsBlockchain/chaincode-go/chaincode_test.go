@@ -0,0 +1,519 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+func TestToHundredths(t *testing.T) {
+	tests := []struct {
+		value float64
+		want  int64
+	}{
+		{0, 0},
+		{3, 300},
+		{0.5, 50},
+		{0.125, 13},  // half rounds up
+		{8.125, 813}, // half rounds up
+		{9.375, 938}, // half rounds up
+		{7.666, 767},
+		{7.664, 766},
+	}
+	for _, tt := range tests {
+		if got := toHundredths(tt.value); got != tt.want {
+			t.Errorf("toHundredths(%v) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestGPAQuotient(t *testing.T) {
+	tests := []struct {
+		name           string
+		weightedPoints int64
+		credits        int64
+		want           int64
+	}{
+		{"no credits", 0, 0, 0},
+		{"exact", 900 * 300, 300, 900},
+		{"rounds down", 900*300 + 800*400, 700, 843}, // 842.857...
+		{"half rounds up", 162500, 200, 813},         // 812.5
+		{"just below half", 162499, 200, 812},
+	}
+	for _, tt := range tests {
+		if got := gpaQuotient(tt.weightedPoints, tt.credits); got != tt.want {
+			t.Errorf("%s: gpaQuotient(%d, %d) = %d, want %d", tt.name, tt.weightedPoints, tt.credits, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateGrades(t *testing.T) {
+	// A scheme with a pass/fail grade earning credits outside the GPA and an audit grade
+	// counting toward neither
+	passFail := &GradingScheme{
+		SchemeID: "TEST-PF",
+		Grades: []GradeDefinition{
+			{Grade: GradeS, Points: 10, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+			{Grade: GradeB, Points: 8, Passing: true, CountsTowardCredits: true, CountsTowardGPA: true},
+			{Grade: "PF", Points: 0, Passing: true, CountsTowardCredits: true, CountsTowardGPA: false},
+			{Grade: "AU", Points: 0, Passing: true, CountsTowardCredits: false, CountsTowardGPA: false},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		scheme         *GradingScheme
+		courses        []Course
+		wantGPACredits float64
+		wantEarned     float64
+		wantSGPA       float64
+	}{
+		{
+			name:           "default scheme",
+			scheme:         &defaultGradingScheme,
+			courses:        []Course{{Credits: 4, Grade: GradeS}, {Credits: 3, Grade: GradeA}},
+			wantGPACredits: 7, wantEarned: 7, wantSGPA: 9.57, // 67/7 = 9.571...
+		},
+		{
+			name:           "failed course counts toward the GPA only",
+			scheme:         &defaultGradingScheme,
+			courses:        []Course{{Credits: 4, Grade: GradeS}, {Credits: 3, Grade: GradeA}, {Credits: 3, Grade: GradeU}},
+			wantGPACredits: 10, wantEarned: 7, wantSGPA: 6.7,
+		},
+		{
+			name:           "half credits round half up",
+			scheme:         &defaultGradingScheme,
+			courses:        []Course{{Credits: 1.5, Grade: GradeA}, {Credits: 2.5, Grade: GradeC}},
+			wantGPACredits: 4, wantEarned: 4, wantSGPA: 7.75, // 31/4
+		},
+		{
+			name:           "non-GPA grades",
+			scheme:         passFail,
+			courses:        []Course{{Credits: 4, Grade: GradeS}, {Credits: 2, Grade: "PF"}, {Credits: 1, Grade: "AU"}},
+			wantGPACredits: 4, wantEarned: 6, wantSGPA: 10,
+		},
+		{
+			name:           "only non-GPA grades",
+			scheme:         passFail,
+			courses:        []Course{{Credits: 2, Grade: "PF"}},
+			wantGPACredits: 0, wantEarned: 2, wantSGPA: 0,
+		},
+		{
+			name:           "grades outside the scheme are ignored",
+			scheme:         passFail,
+			courses:        []Course{{Credits: 3, Grade: GradeB}, {Credits: 3, Grade: GradeA}},
+			wantGPACredits: 3, wantEarned: 3, wantSGPA: 8,
+		},
+	}
+	for _, tt := range tests {
+		gpaCredits, earned, sgpa := calculateGrades(tt.courses, tt.scheme)
+		if gpaCredits != tt.wantGPACredits || earned != tt.wantEarned || sgpa != tt.wantSGPA {
+			t.Errorf("%s: calculateGrades = (%v, %v, %v), want (%v, %v, %v)", tt.name,
+				gpaCredits, earned, sgpa, tt.wantGPACredits, tt.wantEarned, tt.wantSGPA)
+		}
+	}
+}
+
+func TestCumulativeGPA(t *testing.T) {
+	legacy := &AcademicRecord{RecordID: "R1", Semester: 1, SGPA: 8.5, TotalCredits: 20}
+	graded := &AcademicRecord{RecordID: "R2", Semester: 2, SGPA: 9.17, TotalCredits: 24,
+		GradingSchemeID: DefaultGradingSchemeID, GPACredits: 24, EarnedCredits: 21}
+	passFail := &AcademicRecord{RecordID: "R3", Semester: 3, SGPA: 7.33, TotalCredits: 22,
+		GradingSchemeID: "TEST-PF", GPACredits: 18, EarnedCredits: 22}
+
+	tests := []struct {
+		name           string
+		records        []*AcademicRecord
+		wantCGPA       float64
+		wantGPACredits float64
+		wantEarned     float64
+	}{
+		{"no records", nil, 0, 0, 0},
+		// Records graded before schemes count all their credits
+		{"legacy record", []*AcademicRecord{legacy}, 8.5, 20, 20},
+		// (8.5*20 + 9.17*24) / 44 = 8.8654...
+		{"legacy and graded", []*AcademicRecord{legacy, graded}, 8.87, 44, 41},
+		// (8.5*20 + 9.17*24 + 7.33*18) / 62 = 8.4212...
+		{"non-GPA credits", []*AcademicRecord{legacy, graded, passFail}, 8.42, 62, 63},
+	}
+	for _, tt := range tests {
+		// Every order of the records gives the same result
+		permute(tt.records, func(records []*AcademicRecord) {
+			cgpa, gpaCredits, earned := cumulativeGPA(records)
+			if cgpa != tt.wantCGPA || gpaCredits != tt.wantGPACredits || earned != tt.wantEarned {
+				t.Errorf("%s: cumulativeGPA(%s) = (%v, %v, %v), want (%v, %v, %v)", tt.name, recordIDs(records),
+					cgpa, gpaCredits, earned, tt.wantCGPA, tt.wantGPACredits, tt.wantEarned)
+			}
+		})
+	}
+}
+
+// permute calls fn with every ordering of records
+func permute(records []*AcademicRecord, fn func([]*AcademicRecord)) {
+	var walk func(k int)
+	walk = func(k int) {
+		if k == len(records) {
+			fn(records)
+			return
+		}
+		for i := k; i < len(records); i++ {
+			records[k], records[i] = records[i], records[k]
+			walk(k + 1)
+			records[k], records[i] = records[i], records[k]
+		}
+	}
+	walk(0)
+}
+
+// recordIDs lists the IDs of records in order
+func recordIDs(records []*AcademicRecord) []string {
+	ids := make([]string, 0, len(records))
+	for _, record := range records {
+		ids = append(ids, record.RecordID)
+	}
+	return ids
+}
+
+// testStub completes the private data operations shimtest.MockStub leaves unimplemented and
+// reports function as the invoked transaction
+type testStub struct {
+	*shimtest.MockStub
+	function string
+}
+
+func (stub *testStub) GetFunctionAndParameters() (string, []string) {
+	return stub.function, nil
+}
+
+func (stub *testStub) DelPrivateData(collection, key string) error {
+	delete(stub.PvtState[collection], key)
+	return nil
+}
+
+func (stub *testStub) GetPrivateDataHash(collection, key string) ([]byte, error) {
+	value, _ := stub.GetPrivateData(collection, key)
+	if value == nil {
+		return nil, nil
+	}
+	hash := sha256.Sum256(value)
+	return hash[:], nil
+}
+
+// testIdentity is a client identity with a fixed MSP ID and attributes
+type testIdentity struct {
+	mspID      string
+	attributes map[string]string
+}
+
+func (id *testIdentity) GetID() (string, error) { return "x509::CN=admin::CN=ca", nil }
+
+func (id *testIdentity) GetMSPID() (string, error) { return id.mspID, nil }
+
+func (id *testIdentity) GetAttributeValue(name string) (string, bool, error) {
+	value, found := id.attributes[name]
+	return value, found, nil
+}
+
+func (id *testIdentity) AssertAttributeValue(name, value string) error {
+	if id.attributes[name] != value {
+		return fmt.Errorf("attribute %s is not %s", name, value)
+	}
+	return nil
+}
+
+func (id *testIdentity) GetX509Certificate() (*x509.Certificate, error) { return nil, nil }
+
+// newTestContext returns a transaction context for an NITW administrator
+func newTestContext() *contractapi.TransactionContext {
+	stub := &testStub{MockStub: shimtest.NewMockStub("academic-records", nil)}
+	stub.MockTransactionStart("tx1")
+	stub.TxTimestamp = &timestamp.Timestamp{Seconds: 1760000000}
+
+	ctx := &contractapi.TransactionContext{}
+	ctx.SetStub(stub)
+	ctx.SetClientIdentity(&testIdentity{mspID: NITWarangalMSP, attributes: map[string]string{}})
+	return ctx
+}
+
+// seedStudent stores a student with the given CGPA and credits earned
+func seedStudent(t *testing.T, ctx *contractapi.TransactionContext, rollNumber string, cgpa, earned float64) {
+	t.Helper()
+	studentJSON, _ := json.Marshal(Student{
+		RollNumber: rollNumber, Name: "Test Student", Department: "CSE", EnrollmentYear: 2022,
+		Status: StatusActive, CurrentCGPA: cgpa, TotalCreditsEarned: earned,
+	})
+	if err := putAssetState(ctx, DocTypeStudent, rollNumber, studentJSON); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// seedRecord stores a 20-credit record with its student~record index entry
+func seedRecord(t *testing.T, ctx *contractapi.TransactionContext, s *SmartContract,
+	recordID, rollNumber string, semester int, status string, sgpa, cgpa float64) {
+	t.Helper()
+	record := &AcademicRecord{
+		RecordID: recordID, StudentID: rollNumber, Department: "CSE", Semester: semester,
+		Courses: []Course{{CourseCode: "CS101", Credits: 20, Grade: GradeB}}, TotalCredits: 20,
+		SGPA: sgpa, CGPA: cgpa, Status: status,
+		GradingSchemeID: DefaultGradingSchemeID, GPACredits: 20, EarnedCredits: 20,
+	}
+	if err := s.putAcademicRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	key, _ := ctx.GetStub().CreateCompositeKey(StudentRecordKey, []string{rollNumber, recordID})
+	if err := ctx.GetStub().PutState(key, []byte{0x00}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// checkCGPAs compares the stored CGPA of records and of the student
+func checkCGPAs(t *testing.T, ctx *contractapi.TransactionContext, s *SmartContract,
+	rollNumber string, wantStudent, wantEarned float64, wantRecords map[string]float64) {
+	t.Helper()
+	for recordID, want := range wantRecords {
+		record, err := s.readAcademicRecord(ctx, recordID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.CGPA != want {
+			t.Errorf("record %s: CGPA = %v, want %v", recordID, record.CGPA, want)
+		}
+	}
+	student, err := s.readStudent(ctx, rollNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if student.CurrentCGPA != wantStudent || student.TotalCreditsEarned != wantEarned {
+		t.Errorf("student: CGPA, credits = %v, %v, want %v, %v",
+			student.CurrentCGPA, student.TotalCreditsEarned, wantStudent, wantEarned)
+	}
+}
+
+func TestApproveOutOfOrder(t *testing.T) {
+	ctx, s := newTestContext(), &SmartContract{}
+	// Semester 5 was finalized before semester 3, so its CGPA leaves semester 3 out
+	seedStudent(t, ctx, "22CS1001", 7.5, 40)
+	seedRecord(t, ctx, s, "REC-S1", "22CS1001", 1, RecordFinalized, 8, 8)
+	seedRecord(t, ctx, s, "REC-S5", "22CS1001", 5, RecordFinalized, 7, 7.5)
+	seedRecord(t, ctx, s, "REC-S3", "22CS1001", 3, RecordSubmitted, 9, 0)
+
+	if err := s.ApproveAcademicRecord(ctx, "REC-S3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Semester 3 counts semesters 1 and 3; semester 5 and the student count all three
+	checkCGPAs(t, ctx, s, "22CS1001", 8, 60, map[string]float64{"REC-S1": 8, "REC-S3": 8.5, "REC-S5": 8})
+}
+
+func TestApproveRejectsSecondRecordForSemester(t *testing.T) {
+	ctx, s := newTestContext(), &SmartContract{}
+	seedStudent(t, ctx, "22CS1001", 8, 20)
+	seedRecord(t, ctx, s, "REC-S1", "22CS1001", 1, RecordFinalized, 8, 8)
+	seedRecord(t, ctx, s, "REC-S1-DUP", "22CS1001", 1, RecordSubmitted, 9, 0)
+
+	err := s.ApproveAcademicRecord(ctx, "REC-S1-DUP")
+	if err == nil || !strings.Contains(err.Error(), "supersede it first") {
+		t.Fatalf("ApproveAcademicRecord = %v, want an already released error", err)
+	}
+	if _, err := s.replaceableSemesterRecord(ctx, "22CS1001", 1); err == nil {
+		t.Error("replaceableSemesterRecord allowed another record for a semester that has one")
+	}
+	checkCGPAs(t, ctx, s, "22CS1001", 8, 20, map[string]float64{"REC-S1": 8})
+}
+
+func TestRejectRefusesReleasedRecords(t *testing.T) {
+	for _, status := range []string{RecordApproved, RecordFinalized} {
+		ctx, s := newTestContext(), &SmartContract{}
+		seedStudent(t, ctx, "22CS1001", 8, 20)
+		seedRecord(t, ctx, s, "REC-S1", "22CS1001", 1, status, 8, 8)
+
+		err := s.RejectRecord(ctx, "REC-S1", "grades entered for the wrong student")
+		if err == nil || !strings.Contains(err.Error(), "cannot reject") {
+			t.Errorf("RejectRecord of a %s record = %v, want a cannot reject error", status, err)
+		}
+		record, err := s.readRecordState(ctx, "REC-S1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Status != status || record.GradesCollection != "" {
+			t.Errorf("%s record: status %s, grades collection %q after RejectRecord", status, record.Status, record.GradesCollection)
+		}
+		checkCGPAs(t, ctx, s, "22CS1001", 8, 20, map[string]float64{"REC-S1": 8})
+	}
+}
+
+func TestSupersedeReleasedRecordRecomputesCGPA(t *testing.T) {
+	ctx, s := newTestContext(), &SmartContract{}
+	seedStudent(t, ctx, "22CS1001", 8, 60)
+	seedRecord(t, ctx, s, "REC-S1", "22CS1001", 1, RecordFinalized, 8, 8)
+	seedRecord(t, ctx, s, "REC-S2", "22CS1001", 2, RecordFinalized, 9, 8.5)
+	seedRecord(t, ctx, s, "REC-S3", "22CS1001", 3, RecordFinalized, 7, 8)
+
+	if err := s.SupersedeAcademicRecord(ctx, "REC-S2", "grades entered for the wrong student"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Semester 2 no longer counts toward semester 3 or the student
+	checkCGPAs(t, ctx, s, "22CS1001", 7.5, 40, map[string]float64{"REC-S1": 8, "REC-S3": 7.5})
+
+	// The superseded record is the one a corrected semester 2 record replaces
+	replaced, err := s.replaceableSemesterRecord(ctx, "22CS1001", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaced == nil || replaced.RecordID != "REC-S2" || replaced.Status != RecordSuperseded {
+		t.Errorf("replaceableSemesterRecord = %+v, want superseded REC-S2", replaced)
+	}
+
+	// Approving the correction brings semester 2 back into every later CGPA
+	seedRecord(t, ctx, s, "REC-S2-FIX", "22CS1001", 2, RecordSubmitted, 10, 0)
+	if err := s.ApproveAcademicRecord(ctx, "REC-S2-FIX"); err != nil {
+		t.Fatal(err)
+	}
+	checkCGPAs(t, ctx, s, "22CS1001", 8.33, 60, map[string]float64{"REC-S1": 8, "REC-S2-FIX": 9, "REC-S3": 8.33})
+}
+
+func TestPrivateGradesAreSalted(t *testing.T) {
+	ctx, s := newTestContext(), &SmartContract{}
+	stub := ctx.GetStub().(*testStub)
+
+	// The same grades under two salts hash differently, and neither salt is public
+	hashes := map[string]bool{}
+	for i, salt := range []string{"0123456789abcdef", "fedcba9876543210"} {
+		stub.TransientMap = map[string][]byte{"salt": []byte(salt)}
+		recordID := fmt.Sprintf("REC-%d", i)
+		seedRecord(t, ctx, s, recordID, "22CS1001", 1, RecordSubmitted, 8, 0)
+
+		record, err := s.readRecordState(ctx, recordID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.GradesSalt != "" || record.Courses != nil {
+			t.Errorf("stub of %s carries private fields: %+v", recordID, record)
+		}
+		hashes[record.GradesHash] = true
+
+		private, err := s.readAcademicRecord(ctx, recordID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if private.GradesSalt != hex.EncodeToString([]byte(salt)) {
+			t.Errorf("private %s: salt = %q", recordID, private.GradesSalt)
+		}
+	}
+	if len(hashes) != 2 {
+		t.Error("records with the same grades and different salts have the same hash")
+	}
+
+	// A short salt is refused; a record stored without one stays readable
+	stub.TransientMap = map[string][]byte{"salt": []byte("short")}
+	seedRecord(t, ctx, s, "REC-LEGACY", "22CS1001", 2, RecordSubmitted, 8, 0)
+	if _, err := s.readAcademicRecord(ctx, "REC-LEGACY"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gradesSalt(ctx); err == nil {
+		t.Error("gradesSalt accepted a salt shorter than the minimum")
+	}
+
+	// Released grades are public and keep no salt
+	record, err := s.readAcademicRecord(ctx, "REC-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	record.Status = RecordFinalized
+	if err := s.putAcademicRecord(ctx, record); err != nil {
+		t.Fatal(err)
+	}
+	released, err := s.readRecordState(ctx, "REC-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if released.GradesSalt != "" {
+		t.Errorf("released record keeps its salt %q", released.GradesSalt)
+	}
+}
+
+func TestUpdateDocumentStatus(t *testing.T) {
+	ctx, s := newTestContext(), &SmartContract{}
+	seedStudent(t, ctx, "22CS1001", 0, 0)
+	for _, doc := range []*DocumentUpload{
+		{DocID: "DOC-1", StudentID: "22CS1001", DocumentType: "GRADE_SHEET", Status: DocStatusCurrent},
+		{DocID: "DOC-2", StudentID: "22CS1001", DocumentType: "AADHAAR", Status: DocStatusCurrent},
+	} {
+		if err := s.putDocument(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpdateDocumentStatus(ctx, doc.DocID, "UNDER_REVIEW", "reviewer"); err != nil {
+			t.Fatalf("%s: %v", doc.DocID, err)
+		}
+		if err := s.UpdateDocumentStatus(ctx, doc.DocID, "APPROVED", "reviewer"); err == nil {
+			t.Errorf("%s: UpdateDocumentStatus skipped a stage", doc.DocID)
+		}
+
+		stored, err := s.readDocument(ctx, doc.DocID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stored.DocumentStatus != "UNDER_REVIEW" || stored.StatusUpdatedBy != "reviewer" || stored.StatusUpdatedAt.Unix() != 1760000000 {
+			t.Errorf("%s: status %s by %s at %v", doc.DocID, stored.DocumentStatus, stored.StatusUpdatedBy, stored.StatusUpdatedAt)
+		}
+	}
+
+	// The sensitive document stays out of public state
+	if value, _ := getAssetState(ctx, DocTypeDocument, "DOC-2"); value != nil {
+		t.Error("sensitive document written to public state")
+	}
+
+	// A department may only update documents of its own students
+	ctx.SetClientIdentity(&testIdentity{mspID: DepartmentsMSP, attributes: map[string]string{"department": "ECE"}})
+	if err := s.UpdateDocumentStatus(ctx, "DOC-1", "UPLOADED", "reviewer"); err == nil {
+		t.Error("a department updated a document of another department's student")
+	}
+}
+
+func TestPolicyCoverage(t *testing.T) {
+	// main refuses to start the chaincode unless every transaction has a policy
+	if err := checkPolicyCoverage(new(SmartContract)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnforceTransactionPolicy(t *testing.T) {
+	admin := &testIdentity{mspID: NITWarangalMSP, attributes: map[string]string{"role": RoleExamSection}}
+	student := &testIdentity{mspID: NITWarangalMSP, attributes: map[string]string{"role": RoleStudent, "rollNumber": "22CS1001"}}
+	faculty := &testIdentity{mspID: DepartmentsMSP, attributes: map[string]string{"role": RoleFaculty, "department": "CSE"}}
+
+	tests := []struct {
+		function string
+		caller   *testIdentity
+		allowed  bool
+	}{
+		// Admin-only approvals rely on the policy table alone
+		{"ExamSectionApprove", admin, true},
+		{"ExamSectionApprove", student, false},
+		{"ExamSectionApprove", faculty, false},
+		{"DeanAcademicApprove", admin, true},
+		{"DeanAcademicApprove", student, false},
+		{"DeanAcademicApprove", faculty, false},
+		{"FacultyApprove", faculty, true},
+		{"UnknownTransaction", admin, false},
+	}
+	for _, tt := range tests {
+		ctx := newTestContext()
+		ctx.GetStub().(*testStub).function = "SmartContract:" + tt.function
+		ctx.SetClientIdentity(tt.caller)
+		err := enforceTransactionPolicy(ctx)
+		if (err == nil) != tt.allowed {
+			t.Errorf("%s by %s (role %s): err = %v, want allowed %v", tt.function, tt.caller.mspID,
+				tt.caller.attributes["role"], err, tt.allowed)
+		}
+	}
+}
@@ -118,7 +118,7 @@ case "$1" in
   
   # Student queries
   students)
-    query "All Students" '{"function":"GetAllStudents","Args":[]}'
+    query "All Students" '{"function":"GetAllStudents","Args":["","100"]}'
     ;;
   student)
     if [ -z "$2" ]; then
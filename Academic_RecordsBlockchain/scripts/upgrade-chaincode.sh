@@ -165,3 +165,14 @@ echo ""
 echo "🧪 Test the new chaincode function:"
 echo "   docker exec cli peer chaincode query -C academic-records-channel -n academic-records -c '{\"Args\":[\"GetStudentsByDepartment\",\"CSE\"]}'"
 echo ""
+echo "🔑 If upgrading a ledger written before typed asset keys, run MigrateAssetKeys as an admin"
+echo "   until it reports done (pass the returned nextKey as startKey):"
+echo "   peer chaincode invoke ... -c '{\"Args\":[\"MigrateAssetKeys\",\"\",\"200\"]}'"
+echo "   Documents now carry docType \"document\"; clients must read the document type"
+echo "   (AADHAAR, GRADE_SHEET, ...) from documentType instead of docType."
+echo "   The first batch also moves AADHAAR/PHOTO metadata still in public state to private data."
+echo "   Academic records created before this version have no academicYear and are not"
+echo "   returned by QueryRecordsByDepartment when a year filter is given."
+echo "   CreateAcademicRecord now needs a random \"salt\" (at least 16 bytes) in transient data"
+echo "   next to \"courses\"."
+echo ""